@@ -18,19 +18,90 @@ const DEFAULT_KEY_DERIVATION_ITERATIONS = 1000000
 // DEFAULT_KEY_DERIVATION_LENGTH is default length of the derived key, the size of 32 internally selects AES-256 as the cipher
 const DEFAULT_KEY_DERIVATION_LENGTH = 32
 
+// DEFAULT_OUTPUT_MODE is default permission mode of the output files
+const DEFAULT_OUTPUT_MODE = "0600"
+
+// DEFAULT_FORMAT is default output format of the encrypted data
+const DEFAULT_FORMAT = "json-b64"
+
+// DEFAULT_COMPRESSION is default compression algorithm
+const DEFAULT_COMPRESSION = "none"
+
+// DEFAULT_MAX_DECOMPRESSED_SIZE is default limit of decompressed plaintext size in bytes
+const DEFAULT_MAX_DECOMPRESSED_SIZE = 1 << 30
+
+// DEFAULT_PADDING is default padding scheme of the plaintext
+const DEFAULT_PADDING = "none"
+
+// DEFAULT_PAPER_SIZE is default page size of the paper backup
+const DEFAULT_PAPER_SIZE = "a4"
+
+// DEFAULT_PAPER_FORMAT is default format of the encrypted data printed on the paper backup
+const DEFAULT_PAPER_FORMAT = "binary"
+
+// DEFAULT_ECC_PARITY is default number of Reed-Solomon parity bytes per codeword of the text output, zero disables error correction
+const DEFAULT_ECC_PARITY = 0
+
+// DEFAULT_STEGO_FORMAT is default format of the encrypted data hidden inside an image
+const DEFAULT_STEGO_FORMAT = "binary"
+
+// DEFAULT_BIP39_MODE is default mode of BIP39 mnemonic validation
+const DEFAULT_BIP39_MODE = "auto"
+
+// DEFAULT_SHOW_TIMEOUT is default number of seconds the decrypted secret is shown for
+const DEFAULT_SHOW_TIMEOUT = 30
+
+// DEFAULT_EDIT_TMPDIR is default RAM-backed directory the plaintext is written to while it's edited
+const DEFAULT_EDIT_TMPDIR = "/dev/shm"
+
+// DEFAULT_KEYSTORE_KDF is default key derivation function of Ethereum keystore files
+const DEFAULT_KEYSTORE_KDF = "scrypt"
+
+// DEFAULT_SCRYPT_N is default scrypt CPU/memory cost of Ethereum keystore files, the one used by geth
+const DEFAULT_SCRYPT_N = 262144
+
+// DEFAULT_PBKDF2_ITERATIONS is default amount of PBKDF2 iterations of Ethereum keystore files
+const DEFAULT_PBKDF2_ITERATIONS = 262144
+
 // DEFAULT_QR_RECOVERY_LEVEL specifies default error recovery level for the QR code generation process
 const DEFAULT_QR_RECOVERY_LEVEL = "medium"
 
 // DEFAULT_QR_SIZE specifies default image size in pixels for the QR code generation process
 const DEFAULT_QR_SIZE = 1200
 
+// DEFAULT_QR_FORMAT specifies default output format of QR codes
+const DEFAULT_QR_FORMAT = "png"
+
+// DEFAULT_QR_ENCODING specifies default encoding of QR code payloads
+const DEFAULT_QR_ENCODING = "none"
+
+// DEFAULT_QR_MODULE_SIZE specifies default module size of vector QR codes in millimeters
+const DEFAULT_QR_MODULE_SIZE = 1.0
+
+// DEFAULT_ANIMATION_QR_SIZE specifies default frame size in pixels of QR code animations
+const DEFAULT_ANIMATION_QR_SIZE = 600
+
+// DEFAULT_FRAME_RATE specifies default number of frames per second of QR code animations
+const DEFAULT_FRAME_RATE = 5
+
+// DEFAULT_FOUNTAIN_BLOCK_SIZE specifies default number of payload bytes carried by a single animation frame
+const DEFAULT_FOUNTAIN_BLOCK_SIZE = 256
+
 type (
 	// Config is the application configuration
 	Config struct {
 		// InputPath is a path to the input file
 		InputPath string
+		// Prompt is a flag to read the plaintext through a hidden terminal prompt
+		Prompt bool
+		// Stdin is a flag to read the plaintext from stdin
+		Stdin bool
 		// OutputPath is a path to the output file
 		OutputPath string
+		// OutputMode is a permission mode of the output files (octal)
+		OutputMode string
+		// Force is a flag to allow overwriting of existing output files
+		Force bool
 		// MinPwdLength is a minimal requirement for encryption password length
 		MinPwdLength int
 		// MaxPwdLength is a maximum encryption password length
@@ -43,14 +114,81 @@ type (
 		KeyDerivationLength int
 		// NonceLength is a length of nonce used together with the key during encryption process
 		NonceLength int
-		// DisableBase64Processing is a flag to determine if wrapping/unwrapping of DTO package should be performed using Base64
+		// DisableBase64Processing is a flag to determine if wrapping of DTO package should be performed using Base64 on encryption
 		DisableBase64Processing bool
+		// Format is an output format of the encrypted data (json, json-b64, cbor, binary)
+		Format string
+		// Armor is a flag to wrap the output into PEM-like ASCII armor
+		Armor bool
+		// ArmorComment is an optional comment added to the armor headers
+		ArmorComment string
+		// Encoding is an optional text encoding with checksums wrapping the output
+		// (base64, base64url, base32, base58check, hex, bech32)
+		Encoding string
+		// ECCParity is the number of Reed-Solomon parity bytes per 255-byte codeword of armored or Base32 output
+		ECCParity int
+		// DisableMetadata is a flag to disable storing of the original file metadata inside the encrypted payload
+		DisableMetadata bool
+		// EncryptMetadata is a flag to determine if the metadata is encrypted or only authenticated
+		EncryptMetadata bool
+		// PreserveXattrs is a flag to store and restore extended file attributes along with the other metadata
+		PreserveXattrs bool
+		// DisableMetadataRestore is a flag to disable restoring of the original file name and attributes on decryption
+		DisableMetadataRestore bool
+		// Compression is an algorithm used to compress the plaintext before encryption (none, gzip, zstd)
+		Compression string
+		// MaxDecompressedSize is a limit of decompressed plaintext size in bytes
+		MaxDecompressedSize int64
+		// Padding is a scheme used to pad the plaintext to hide its length (none, padme, block:N)
+		Padding string
+		// PaperLabel is a free-text label printed on the paper backup
+		PaperLabel string
+		// PaperSize is a page size of the paper backup (a4, letter)
+		PaperSize string
 		// EnableQRGeneration is a flag to enable generation of QR code alongside the encoded output (PNG image)
 		EnableQRGeneration bool
 		// QRRecoveryLevel is a level of error recovery (low, medium, high, highest)
 		QRRecoveryLevel string
 		// QRSize is the size of encoded image
 		QRSize int
+		// QRTiled is a flag to place all QR codes of a split output on a single image
+		QRTiled bool
+		// QREncoding is the encoding of QR code payloads (none, base45, base32)
+		QREncoding string
+		// QRFormat is the output format of QR codes (png, svg, pdf, terminal)
+		QRFormat string
+		// QRModuleSize is the module size of vector QR codes in millimeters
+		QRModuleSize float64
+		// QRInverted is a flag to draw dark modules in the terminal output, for terminals with light background
+		QRInverted bool
+		// AnimationFormat is the format of QR code animation (gif, apng)
+		AnimationFormat string
+		// FrameRate is the number of animation frames per second
+		FrameRate int
+		// FountainBlockSize is the number of payload bytes carried by a single animation frame
+		FountainBlockSize int
+		// FountainFrames is the number of generated animation frames, twice the number of blocks by default
+		FountainFrames int
+		// ShowTimeout is the number of seconds the decrypted secret is shown for, zero disables the timeout
+		ShowTimeout int
+		// ShowPerLine is a flag to reveal the decrypted secret line by line
+		ShowPerLine bool
+		// Editor is the command launched to edit the plaintext
+		Editor string
+		// EditTmpDir is the RAM-backed directory the plaintext is written to while it's edited
+		EditTmpDir string
+		// Reformat is a flag to allow saving of the edited file in a different format
+		Reformat bool
+		// ExecOnly is the list of variables passed to the executed command, all variables are passed if it's empty
+		ExecOnly []string
+		// BIP39 is the mode of BIP39 mnemonic validation (auto, on, off)
+		BIP39 string
+		// KeystoreKDF is the key derivation function of Ethereum keystore files (scrypt, pbkdf2)
+		KeystoreKDF string
+		// ScryptN is the scrypt CPU/memory cost of Ethereum keystore files
+		ScryptN int
+		// PBKDF2Iterations is the amount of PBKDF2 iterations of Ethereum keystore files
+		PBKDF2Iterations int
 	}
 )
 
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newConvertCmd(cfg *Config) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "convert INPUT_FILEPATH",
+		Short: "Converts an encrypted file into another format without decryption",
+		Long: `Converts an encrypted file into another format without decryption.
+The ciphertext is moved as is, so no password is required.`,
+		Args: cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputMode(cfg.OutputMode); err != nil {
+				return err
+			}
+			if err := validateFormat(cfg.Format); err != nil {
+				return err
+			}
+			if err := validateEncoding(cfg.Encoding, cfg.Armor); err != nil {
+				return err
+			}
+			return validateECC(cfg.ECCParity, cfg.Armor, cfg.Encoding)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg.InputPath = args[0]
+			cmd.SilenceUsage = true
+			if cfg.OutputPath == "" {
+				cfg.OutputPath = cfg.InputPath + "." + cfg.Format
+			}
+			return newSession(cfg).Convert(cfg.InputPath, cfg.OutputPath)
+		},
+	}
+
+	addOutputFlags(cmd, cfg, "Redirect output into the specified file. "+
+		"By default the output is saved at \"INPUT_FILEPATH.FORMAT\".")
+	addFormatFlags(cmd, cfg)
+
+	return cmd
+}
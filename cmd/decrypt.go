@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func newDecryptCmd(cfg *Config) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "decrypt [INPUT_FILEPATH] [QR_IMAGE_FILEPATH...]",
+		Short: "Decrypts a file with password",
+		Long: "Decrypts a file with password.\n\n" +
+			"A payload split across several QR code images is reassembled from all the images provided " +
+			"(in any order), e.g. aesgcm decrypt backup.aes.*.png\n\n" +
+			"Without input files the ciphertext encoded with --encoding words is entered word by word, " +
+			"words are completed as soon as their prefix is unique, e.g. aesgcm decrypt -o seed.txt",
+		Args: cobra.ArbitraryArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 && cfg.OutputPath == "" {
+				return fmt.Errorf("--output is required when the words are entered interactively")
+			}
+			if err := validateBIP39Mode(cfg.BIP39); err != nil {
+				return err
+			}
+			return validateOutputMode(cfg.OutputMode)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return newSession(cfg).Decrypt(args, cfg.OutputPath)
+		},
+	}
+
+	addOutputFlags(cmd, cfg, fmt.Sprintf("Redirect output into the specified file. "+
+		"By default the original file name stored in the metadata is restored next to the input file, "+
+		"otherwise the output is saved at %q.", "INPUT_FILEPATH.txt"))
+
+	cmd.Flags().BoolVar(&cfg.DisableMetadataRestore, "no-restore-metadata", false,
+		"Don't restore the original file name and attributes.")
+	cmd.Flags().BoolVar(&cfg.PreserveXattrs, "preserve-xattrs", false,
+		"Restore extended file attributes along with the other metadata.")
+
+	// kept for compatibility with scripts written before the input format was detected
+	cmd.Flags().BoolVar(&cfg.DisableBase64Processing, "disable-base64", false,
+		"Has no effect, the input format is detected automatically.")
+	cmd.Flags().MarkDeprecated("disable-base64", "the input format is detected automatically")
+
+	cmd.Flags().Int64Var(&cfg.MaxDecompressedSize, "max-decompressed-size", DEFAULT_MAX_DECOMPRESSED_SIZE,
+		"Maximum size of decompressed plaintext in bytes, protects against decompression bombs.")
+
+	addBIP39Flag(cmd, cfg, "Re-check the checksum of BIP39 mnemonic after decryption and warn on mismatch (auto, on, off). "+
+		"In auto mode only the plaintext which looks like a mnemonic is checked.")
+
+	return cmd
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func newEditCmd(cfg *Config) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "edit INPUT_FILEPATH",
+		Short: "Edits an encrypted file in place with an external editor",
+		Long: "Decrypts a file into a private directory on tmpfs, launches $VISUAL or $EDITOR on it and re-encrypts " +
+			"the file in place if the plaintext has been changed. The same password is used, the key derivation parameters " +
+			"are kept unless stronger ones are specified, the salt and nonce are regenerated. " +
+			"The temporary file is overwritten before removal and the original file is left untouched if the editor fails.",
+		Args: cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateBIP39Mode(cfg.BIP39); err != nil {
+				return err
+			}
+			if err := validateFormat(cfg.Format); err != nil {
+				return err
+			}
+			if err := validateEncoding(cfg.Encoding, cfg.Armor); err != nil {
+				return err
+			}
+			return validateECC(cfg.ECCParity, cfg.Armor, cfg.Encoding)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg.InputPath = args[0]
+			cmd.SilenceUsage = true
+			// the parameters of the original file are kept unless the flags are given explicitly
+			for name, value := range map[string]*int{
+				"salt-length":               &cfg.SaltLength,
+				"key-derivation-iterations": &cfg.KeyDerivationIterations,
+				"key-derivation-length":     &cfg.KeyDerivationLength,
+			} {
+				if !cmd.Flags().Changed(name) {
+					*value = 0
+				}
+			}
+			// the file is replaced atomically with the permissions of the original one
+			cfg.Force = true
+			cfg.OutputMode = DEFAULT_OUTPUT_MODE
+			if info, err := os.Stat(cfg.InputPath); err == nil {
+				cfg.OutputMode = fmt.Sprintf("%04o", info.Mode().Perm())
+			}
+			return newSession(cfg).Edit(cfg.InputPath)
+		},
+	}
+
+	cmd.Flags().StringVar(&cfg.Editor, "editor", defaultEditor(),
+		"Editor command, $VISUAL or $EDITOR by default. GUI editors need a flag to wait for the window to close, e.g. \"code --wait\".")
+	cmd.Flags().StringVar(&cfg.EditTmpDir, "tmpdir", DEFAULT_EDIT_TMPDIR,
+		"RAM-backed directory the plaintext is written to while it's edited.")
+	cmd.Flags().BoolVar(&cfg.Reformat, "reformat", false,
+		"Save the edited file in the format given with the format flags even if it differs from the original one.")
+
+	cmd.Flags().IntVar(&cfg.SaltLength, "salt-length", DEFAULT_SALT_LENGTH,
+		"Salt length, used only if it's longer than the original one.")
+	cmd.Flags().IntVar(&cfg.KeyDerivationIterations, "key-derivation-iterations", DEFAULT_KEY_DERIVATION_ITERATIONS,
+		"Amount of iterations used to derive the key, used only if it's larger than the original one.")
+	cmd.Flags().IntVar(&cfg.KeyDerivationLength, "key-derivation-length", DEFAULT_KEY_DERIVATION_LENGTH,
+		"Length of derived key, used only if it's longer than the original one.")
+
+	addFormatFlags(cmd, cfg)
+
+	cmd.Flags().Int64Var(&cfg.MaxDecompressedSize, "max-decompressed-size", DEFAULT_MAX_DECOMPRESSED_SIZE,
+		"Maximum size of decompressed plaintext in bytes, protects against decompression bombs.")
+	addBIP39Flag(cmd, cfg, "Warn if the edited plaintext isn't a valid BIP39 mnemonic (auto, on, off). "+
+		"In auto mode only the plaintext which looks like a mnemonic is checked.")
+
+	return cmd
+}
+
+// defaultEditor follows the convention of $VISUAL taking precedence over $EDITOR
+func defaultEditor() string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if editor := os.Getenv(name); editor != "" {
+			return editor
+		}
+	}
+	return "vi"
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/d347h-eth/aesgcm/internal/domain"
+
+	"github.com/spf13/cobra"
+)
+
+func newEIP2335Cmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "eip2335",
+		Short: "Converts EIP-2335 validator keystore files to and from encrypted files",
+		Long: `Converts EIP-2335 keystore files of BLS12-381 validator signing keys to and from encrypted files.
+Passwords are normalized the way EIP-2335 requires (NFKD with control characters stripped),
+and the checksum module is validated before the secret is decrypted.`,
+	}
+	cmd.AddCommand(
+		newEIP2335DecryptCmd(NewConfig()),
+		newEIP2335EncryptCmd(NewConfig()),
+		newEIP2335VerifyCmd(NewConfig()),
+	)
+	return cmd
+}
+
+func newEIP2335DecryptCmd(cfg *Config) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "decrypt KEYSTORE_FILEPATH",
+		Short: "Decrypts an EIP-2335 keystore file into an encrypted file",
+		Long: `Decrypts an EIP-2335 keystore file and encrypts the secret key (hex) with the same password.
+The public key, the derivation path, the description and the UUID of the keystore are stored
+after the secret key as "<Field>: <value>" lines, so "eip2335 encrypt" restores them.
+The public key and the derivation path are printed, the metadata is encrypted.`,
+		Args: cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputMode(cfg.OutputMode); err != nil {
+				return err
+			}
+			if err := validateFormat(cfg.Format); err != nil {
+				return err
+			}
+			if err := validateEncoding(cfg.Encoding, cfg.Armor); err != nil {
+				return err
+			}
+			return validateECC(cfg.ECCParity, cfg.Armor, cfg.Encoding)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg.InputPath = args[0]
+			cmd.SilenceUsage = true
+			if cfg.OutputPath == "" {
+				cfg.OutputPath = cfg.InputPath + ".aes"
+			}
+			return newSession(cfg).ValidatorKeystoreDecrypt(cfg.InputPath, cfg.OutputPath)
+		},
+	}
+
+	addOutputFlags(cmd, cfg, fmt.Sprintf("Redirect output into the specified file. "+
+		"By default the output is saved at %q.", "KEYSTORE_FILEPATH.aes"))
+	addKeyDerivationFlags(cmd, cfg)
+	addFormatFlags(cmd, cfg)
+	cmd.Flags().BoolVar(&cfg.DisableMetadata, "no-metadata", false,
+		"Don't store the file name inside the encrypted payload.")
+	cfg.EncryptMetadata = true
+
+	return cmd
+}
+
+func newEIP2335EncryptCmd(cfg *Config) *cobra.Command {
+	var privateKeyPath string
+	var template domain.ValidatorKey
+	var cmd = &cobra.Command{
+		Use:   "encrypt [INPUT_FILEPATH | --privkey-file PRIVATE_KEY_FILEPATH]",
+		Short: "Exports a validator secret key into an EIP-2335 keystore file",
+		Long: `Exports a BLS12-381 secret key (hex, optionally prefixed with 0x) into an EIP-2335 keystore file.
+The key is read either from an encrypted file, whose password is reused for the keystore,
+or from a plaintext file with --privkey-file. The fields stored by "eip2335 decrypt" after the secret key
+are written into the keystore unless overridden with the flags. The public key is derived from the secret key,
+a public key given with --pubkey or stored with the key has to match it.`,
+		Args: cobra.MaximumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if (len(args) == 0) == (privateKeyPath == "") {
+				return fmt.Errorf("either INPUT_FILEPATH or --privkey-file has to be specified")
+			}
+			if err := validateOutputMode(cfg.OutputMode); err != nil {
+				return err
+			}
+			return validateKeystoreKDF(cfg.KeystoreKDF, cfg.ScryptN, cfg.PBKDF2Iterations)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if privateKeyPath != "" {
+				return newSession(cfg).ValidatorKeystoreEncrypt(privateKeyPath, cfg.OutputPath, true, template)
+			}
+			cfg.InputPath = args[0]
+			return newSession(cfg).ValidatorKeystoreEncrypt(cfg.InputPath, cfg.OutputPath, false, template)
+		},
+	}
+
+	addOutputFlags(cmd, cfg, "Redirect output into the specified file. "+
+		"By default the output is saved at \"keystore-<path>-<timestamp>.json\".")
+	cmd.Flags().StringVar(&privateKeyPath, "privkey-file", "",
+		"Plaintext file with the secret key (hex).")
+	cmd.Flags().StringVar(&template.Pubkey, "pubkey", "",
+		"BLS public key (hex) checked against the one derived from the secret key.")
+	cmd.Flags().StringVar(&template.Path, "path", "",
+		"EIP-2334 derivation path written into the keystore, e.g. m/12381/3600/0/0/0.")
+	cmd.Flags().StringVar(&template.Description, "description", "",
+		"Description written into the keystore.")
+	addKeystoreKDFFlags(cmd, cfg)
+	cmd.Flags().IntVarP(&cfg.MinPwdLength, "min-password-length", "p", DEFAULT_PWD_LENGTH,
+		"Minimum password length requirement. The password must be at least this many characters long.")
+	cfg.MaxDecompressedSize = DEFAULT_MAX_DECOMPRESSED_SIZE
+
+	return cmd
+}
+
+func newEIP2335VerifyCmd(cfg *Config) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "verify KEYSTORE_FILEPATH",
+		Short: "Checks the password of an EIP-2335 keystore file",
+		Long:  `Checks the password against the checksum module of an EIP-2335 keystore file, nothing is written.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg.InputPath = args[0]
+			cmd.SilenceUsage = true
+			return newSession(cfg).ValidatorKeystoreVerify(cfg.InputPath)
+		},
+	}
+	return cmd
+}
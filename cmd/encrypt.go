@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/d347h-eth/aesgcm/internal/adapter/session"
+
+	"github.com/spf13/cobra"
+)
+
+func newEncryptCmd(cfg *Config) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "encrypt [INPUT_FILEPATH]",
+		Short: "Encrypts a file with password",
+		Long: "Encrypts a file with password.\n\n" +
+			"A secret can be encrypted straight from memory, so it never touches a plaintext file: " +
+			"--prompt reads it through a hidden multi-line prompt, --stdin reads it from a pipe, " +
+			"e.g. aesgcm encrypt --prompt -o secret.aes\n\n" +
+			"Without input file BIP39 mnemonic is entered word by word with hidden input, e.g. aesgcm encrypt -o seed.aes",
+		Args: cobra.MaximumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 && (cfg.Prompt || cfg.Stdin) {
+				return fmt.Errorf("INPUT_FILEPATH can't be combined with --prompt or --stdin")
+			}
+			if len(args) == 0 && cfg.OutputPath == "" {
+				return fmt.Errorf("--output is required when the plaintext isn't read from a file")
+			}
+			if err := validateBIP39Mode(cfg.BIP39); err != nil {
+				return err
+			}
+			if err := validateOutputMode(cfg.OutputMode); err != nil {
+				return err
+			}
+			if err := validateFormat(cfg.Format); err != nil {
+				return err
+			}
+			if err := validateEncoding(cfg.Encoding, cfg.Armor); err != nil {
+				return err
+			}
+			if err := validateECC(cfg.ECCParity, cfg.Armor, cfg.Encoding); err != nil {
+				return err
+			}
+			if err := validateCompression(cfg.Compression); err != nil {
+				return err
+			}
+			if err := validatePadding(cfg.Padding); err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("qr-format") {
+				cfg.EnableQRGeneration = true
+			}
+			if cfg.EnableQRGeneration {
+				if err := validateQRFormat(cfg.QRFormat, cfg.QRModuleSize); err != nil {
+					return err
+				}
+				if err := validateQREncoding(cfg.QREncoding); err != nil {
+					return err
+				}
+				return validateQRRecoveryLevel(cfg.QRRecoveryLevel)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			source := session.SOURCE_MNEMONIC
+			switch {
+			case cfg.Prompt:
+				source = session.SOURCE_PROMPT
+			case cfg.Stdin:
+				source = session.SOURCE_STDIN
+			case len(args) > 0:
+				source = session.SOURCE_FILE
+				cfg.InputPath = args[0]
+			}
+			if cfg.OutputPath == "" {
+				cfg.OutputPath = cfg.InputPath + ".aes"
+			}
+			return newSession(cfg).Encrypt(source, cfg.InputPath, cfg.OutputPath)
+		},
+	}
+
+	addOutputFlags(cmd, cfg, fmt.Sprintf("Redirect output into the specified file. "+
+		"By default the output is saved at %q.", "INPUT_FILEPATH.aes"))
+
+	cmd.Flags().BoolVar(&cfg.Prompt, "prompt", false,
+		"Read the plaintext through a hidden multi-line terminal prompt with confirmation instead of a file.")
+	cmd.Flags().BoolVar(&cfg.Stdin, "stdin", false,
+		"Read the plaintext from stdin instead of a file, the password is read from the terminal.")
+	cmd.MarkFlagsMutuallyExclusive("prompt", "stdin")
+
+	cmd.Flags().IntVarP(&cfg.MinPwdLength, "min-password-length", "p", DEFAULT_PWD_LENGTH,
+		"Minimum password length requirement. The password must be at least this many characters long.")
+
+	addKeyDerivationFlags(cmd, cfg)
+
+	addFormatFlags(cmd, cfg)
+
+	cmd.Flags().BoolVar(&cfg.DisableMetadata, "no-metadata", false,
+		"Don't store the original file name, permissions and modification time inside the encrypted payload.")
+	cmd.Flags().BoolVar(&cfg.EncryptMetadata, "encrypt-metadata", true,
+		"Encrypt the stored metadata, otherwise it's only authenticated and the file name stays readable.")
+	cmd.Flags().BoolVar(&cfg.PreserveXattrs, "preserve-xattrs", false,
+		"Store extended file attributes along with the other metadata.")
+
+	cmd.Flags().StringVar(&cfg.Compression, "compress", DEFAULT_COMPRESSION,
+		"Compress the plaintext before encryption (none, gzip, zstd). "+
+			"Compression makes ciphertext length depend on the content, which may leak information "+
+			"if an attacker controls a part of the plaintext, so it's disabled by default.")
+
+	cmd.Flags().StringVar(&cfg.Padding, "pad", DEFAULT_PADDING,
+		"Pad the plaintext before encryption to hide its exact length (none, padme, block:N). "+
+			"PADMÉ adds at most 12% overhead, block:N pads to a multiple of N bytes.")
+
+	addBIP39Flag(cmd, cfg, "Validate the words and the checksum of BIP39 mnemonic before encryption (auto, on, off). "+
+		"In auto mode only the plaintext which looks like a mnemonic is validated, --bip39 validates any plaintext.")
+
+	addQRFlags(cmd, cfg)
+
+	return cmd
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func newExecCmd(cfg *Config) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "exec INPUT_FILEPATH -- COMMAND [ARGS...]",
+		Short: "Runs a command with decrypted secrets in its environment",
+		Long: "Decrypts a file with environment variables in dotenv syntax in memory and runs the command " +
+			"with the variables merged into its environment, the plaintext never touches the disk. " +
+			"The command replaces the aesgcm process, so it receives the signals directly and its exit code is preserved.",
+		Args: cobra.MinimumNArgs(2),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.ArgsLenAtDash() != 1 {
+				return fmt.Errorf("the command has to be separated from INPUT_FILEPATH with \"--\"")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg.InputPath = args[0]
+			cmd.SilenceUsage = true
+			return newSession(cfg).Exec(cfg.InputPath, args[1:], cfg.ExecOnly)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&cfg.ExecOnly, "only", nil,
+		"Pass only the listed variables to the command, e.g. --only DB_USER,DB_PASSWORD.")
+	cmd.Flags().Int64Var(&cfg.MaxDecompressedSize, "max-decompressed-size", DEFAULT_MAX_DECOMPRESSED_SIZE,
+		"Maximum size of decompressed plaintext in bytes, protects against decompression bombs.")
+
+	return cmd
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func addOutputFlags(cmd *cobra.Command, cfg *Config, usage string) {
+	cmd.Flags().StringVarP(&cfg.OutputPath, "output", "o", "", usage)
+	cmd.Flags().StringVar(&cfg.OutputMode, "mode", DEFAULT_OUTPUT_MODE,
+		"Permission mode (octal) of the output files.")
+	cmd.Flags().BoolVarP(&cfg.Force, "force", "f", false,
+		"Overwrite output files if they already exist.")
+}
+
+func addKeyDerivationFlags(cmd *cobra.Command, cfg *Config) {
+	cmd.Flags().IntVar(&cfg.SaltLength, "salt-length", DEFAULT_SALT_LENGTH,
+		"Salt length. Salt is used to derive key from the password. Don't change unless you're absolutely confident.")
+	cmd.Flags().IntVar(&cfg.NonceLength, "nonce-length", DEFAULT_NONCE_LENGTH,
+		"Nonce length. Nonce is used together with the key to encrypt data and must be unique per given key. "+
+			"Don't change unless you're absolutely confident.")
+	cmd.Flags().IntVar(&cfg.KeyDerivationIterations, "key-derivation-iterations", DEFAULT_KEY_DERIVATION_ITERATIONS,
+		"Amount of iterations used to derive the key. Don't change unless you're absolutely confident.")
+	cmd.Flags().IntVar(&cfg.KeyDerivationLength, "key-derivation-length", DEFAULT_KEY_DERIVATION_LENGTH,
+		"Length of derived key. Don't change unless you're absolutely confident.")
+}
+
+func addBIP39Flag(cmd *cobra.Command, cfg *Config, usage string) {
+	cmd.Flags().StringVar(&cfg.BIP39, "bip39", DEFAULT_BIP39_MODE, usage)
+	cmd.Flags().Lookup("bip39").NoOptDefVal = "on"
+}
+
+func addKeystoreKDFFlags(cmd *cobra.Command, cfg *Config) {
+	cmd.Flags().StringVar(&cfg.KeystoreKDF, "kdf", DEFAULT_KEYSTORE_KDF,
+		"Key derivation function of the keystore (scrypt, pbkdf2).")
+	cmd.Flags().IntVar(&cfg.ScryptN, "scrypt-n", DEFAULT_SCRYPT_N,
+		"CPU/memory cost of scrypt, a power of 2.")
+	cmd.Flags().IntVar(&cfg.PBKDF2Iterations, "pbkdf2-iterations", DEFAULT_PBKDF2_ITERATIONS,
+		"Amount of PBKDF2 iterations.")
+}
+
+func addFormatFlags(cmd *cobra.Command, cfg *Config) {
+	cmd.Flags().StringVar(&cfg.Format, "format", DEFAULT_FORMAT,
+		"Output format of the encrypted data (json, json-b64, cbor, binary). "+
+			"Binary formats are the most compact ones. The input format is detected automatically.")
+	cmd.Flags().BoolVar(&cfg.DisableBase64Processing, "disable-base64", false,
+		"Output plain JSON without Base64 encoding.")
+	cmd.Flags().MarkDeprecated("disable-base64", "use --format json instead")
+	cmd.Flags().BoolVar(&cfg.Armor, "armor", false,
+		"Wrap the output into PEM-like ASCII armor with 64-column lines and a CRC24 checksum.")
+	cmd.Flags().StringVar(&cfg.ArmorComment, "comment", "",
+		"Add a \"Comment\" header to the ASCII armor.")
+	addECCFlag(cmd, cfg)
+	cmd.Flags().StringVar(&cfg.Encoding, "encoding", "",
+		"Wrap the output with a text encoding carrying checksums, so typos are reported with their position "+
+			"(base64, base64url, base32, base58check, hex, bech32, ur, words). Base32, hex, bech32 and ur are case-insensitive. "+
+			"Bech32 is written as numbered Bech32m lines of at most 90 characters. "+
+			"UR (ur:bytes with bytewords) is readable by hardware wallets, large outputs are split into multipart UR. "+
+			"Words are BIP39 English words with a checksum word after every 7 words, for steel plates and reading aloud.")
+}
+
+func addECCFlag(cmd *cobra.Command, cfg *Config) {
+	cmd.Flags().IntVar(&cfg.ECCParity, "ecc", DEFAULT_ECC_PARITY,
+		"Add Reed-Solomon parity to armored or Base32 output, the value is the number of parity bytes "+
+			"per 255-byte codeword. N parity bytes repair N bytes of unreadable lines or N/2 mistyped bytes per codeword, "+
+			"e.g. 32 adds 14% overhead.")
+}
+
+func addQRFlags(cmd *cobra.Command, cfg *Config) {
+	cmd.Flags().BoolVar(&cfg.EnableQRGeneration, "qr-enable", false,
+		"Generate a PNG image with QR code alongside the encoded output. "+
+			"Large outputs are split into several QR codes saved as OUTPUT.1.png … OUTPUT.N.png.")
+	cmd.Flags().BoolVar(&cfg.QRTiled, "qr-tile", false,
+		"Place all QR codes of a split output on a single image.")
+	addQREncodingFlag(cmd, cfg)
+	cmd.Flags().StringVar(&cfg.QRFormat, "qr-format", DEFAULT_QR_FORMAT,
+		"QR code output format (png, svg, pdf, terminal). Vector formats keep the exact module size for printing, "+
+			"terminal prints the code with Unicode half blocks instead of saving it. Implies --qr-enable.")
+	cmd.Flags().Float64Var(&cfg.QRModuleSize, "qr-module-size", DEFAULT_QR_MODULE_SIZE,
+		"Module size of SVG and PDF QR codes in millimeters.")
+	cmd.Flags().BoolVar(&cfg.QRInverted, "qr-invert", false,
+		"Draw dark modules in the terminal output, for terminals with light background.")
+	cmd.Flags().StringVar(&cfg.QRRecoveryLevel, "qr-level", DEFAULT_QR_RECOVERY_LEVEL,
+		"Set QR code error recovery level (low, medium, high, highest).")
+	cmd.Flags().IntVar(&cfg.QRSize, "qr-size", DEFAULT_QR_SIZE,
+		"Set QR code image size in pixels (if positive value provided). "+
+			"A negative value causes a variable sized image to be rendered "+
+			"(specified value will be applied as width in pixels for each QR code \"module\").")
+}
+
+func addQREncodingFlag(cmd *cobra.Command, cfg *Config) {
+	cmd.Flags().StringVar(&cfg.QREncoding, "qr-encoding", DEFAULT_QR_ENCODING,
+		"Encoding of QR code payloads (none, base45, base32). Base45 and Base32 encode the binary container "+
+			"with QR alphanumeric characters, which fits about 40% more data per code than the default output.")
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func newKeystoreCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "keystore",
+		Short: "Converts Ethereum keystore files to and from encrypted files",
+		Long: `Converts Ethereum keystore files (Web3 Secret Storage V3, the UTC--… JSON files of geth and most wallets)
+to and from encrypted files. The password is kept, and the address is printed so the key can be verified.`,
+	}
+	cmd.AddCommand(
+		newKeystoreDecryptCmd(NewConfig()),
+		newKeystoreEncryptCmd(NewConfig()),
+	)
+	return cmd
+}
+
+func newKeystoreDecryptCmd(cfg *Config) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "decrypt KEYSTORE_FILEPATH",
+		Short: "Decrypts an Ethereum keystore file into an encrypted file",
+		Long: `Decrypts an Ethereum keystore file and encrypts the private key (hex) with the same password.
+The MAC and the address of the keystore are verified, the metadata is encrypted.`,
+		Args: cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputMode(cfg.OutputMode); err != nil {
+				return err
+			}
+			if err := validateFormat(cfg.Format); err != nil {
+				return err
+			}
+			if err := validateEncoding(cfg.Encoding, cfg.Armor); err != nil {
+				return err
+			}
+			return validateECC(cfg.ECCParity, cfg.Armor, cfg.Encoding)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg.InputPath = args[0]
+			cmd.SilenceUsage = true
+			if cfg.OutputPath == "" {
+				cfg.OutputPath = cfg.InputPath + ".aes"
+			}
+			return newSession(cfg).KeystoreDecrypt(cfg.InputPath, cfg.OutputPath)
+		},
+	}
+
+	addOutputFlags(cmd, cfg, fmt.Sprintf("Redirect output into the specified file. "+
+		"By default the output is saved at %q.", "KEYSTORE_FILEPATH.aes"))
+	addKeyDerivationFlags(cmd, cfg)
+	addFormatFlags(cmd, cfg)
+	cmd.Flags().BoolVar(&cfg.DisableMetadata, "no-metadata", false,
+		"Don't store the file name (the address) inside the encrypted payload.")
+	cfg.EncryptMetadata = true
+
+	return cmd
+}
+
+func newKeystoreEncryptCmd(cfg *Config) *cobra.Command {
+	var privateKeyPath string
+	var cmd = &cobra.Command{
+		Use:   "encrypt [INPUT_FILEPATH | --privkey-file PRIVATE_KEY_FILEPATH]",
+		Short: "Exports a private key into an Ethereum keystore file",
+		Long: `Exports a private key (hex, optionally prefixed with 0x) into an Ethereum keystore file.
+The key is read either from an encrypted file, whose password is reused for the keystore,
+or from a plaintext file with --privkey-file. By default the output is named the way geth names it:
+UTC--<time>--<address>.`,
+		Args: cobra.MaximumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if (len(args) == 0) == (privateKeyPath == "") {
+				return fmt.Errorf("either INPUT_FILEPATH or --privkey-file has to be specified")
+			}
+			if err := validateOutputMode(cfg.OutputMode); err != nil {
+				return err
+			}
+			return validateKeystoreKDF(cfg.KeystoreKDF, cfg.ScryptN, cfg.PBKDF2Iterations)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if privateKeyPath != "" {
+				return newSession(cfg).KeystoreEncrypt(privateKeyPath, cfg.OutputPath, true)
+			}
+			cfg.InputPath = args[0]
+			return newSession(cfg).KeystoreEncrypt(cfg.InputPath, cfg.OutputPath, false)
+		},
+	}
+
+	addOutputFlags(cmd, cfg, "Redirect output into the specified file. "+
+		"By default the output is saved at \"UTC--<time>--<address>\".")
+	cmd.Flags().StringVar(&privateKeyPath, "privkey-file", "",
+		"Plaintext file with the private key (hex).")
+	addKeystoreKDFFlags(cmd, cfg)
+	cmd.Flags().IntVarP(&cfg.MinPwdLength, "min-password-length", "p", DEFAULT_PWD_LENGTH,
+		"Minimum password length requirement. The password must be at least this many characters long.")
+	cfg.MaxDecompressedSize = DEFAULT_MAX_DECOMPRESSED_SIZE
+
+	return cmd
+}
@@ -3,18 +3,43 @@ package main
 import (
 	"fmt"
 	"os"
+	"strconv"
 
 	"github.com/d347h-eth/aesgcm/internal/adapter/session"
 	"github.com/d347h-eth/aesgcm/internal/infra/aesgcm"
+	"github.com/d347h-eth/aesgcm/internal/infra/compression"
+	"github.com/d347h-eth/aesgcm/internal/infra/container"
+	"github.com/d347h-eth/aesgcm/internal/infra/editor"
 	"github.com/d347h-eth/aesgcm/internal/infra/filesystem"
+	"github.com/d347h-eth/aesgcm/internal/infra/paper"
+	"github.com/d347h-eth/aesgcm/internal/infra/process"
+	"github.com/d347h-eth/aesgcm/internal/infra/qrdecoder"
 	"github.com/d347h-eth/aesgcm/internal/infra/qrencoder"
 	"github.com/d347h-eth/aesgcm/internal/infra/randomness"
+	"github.com/d347h-eth/aesgcm/internal/infra/stego"
 	"github.com/d347h-eth/aesgcm/internal/infra/terminal"
+	"github.com/d347h-eth/aesgcm/internal/infra/wordlist"
 	"github.com/d347h-eth/aesgcm/internal/usecase/codec"
+	"github.com/d347h-eth/aesgcm/internal/usecase/dotenv"
+	"github.com/d347h-eth/aesgcm/internal/usecase/eip2335"
+	"github.com/d347h-eth/aesgcm/internal/usecase/fountain"
+	"github.com/d347h-eth/aesgcm/internal/usecase/keystore"
 
 	"github.com/spf13/cobra"
 )
 
+var CompressionAlgorithms = []string{
+	compression.ALGORITHM_NONE,
+	compression.ALGORITHM_GZIP,
+	compression.ALGORITHM_ZSTD,
+}
+
+var BIP39Modes = []string{
+	"auto",
+	"on",
+	"off",
+}
+
 var QRRecoveryLevels = map[string]int{
 	"low":     0,
 	"medium":  1,
@@ -23,118 +48,116 @@ var QRRecoveryLevels = map[string]int{
 }
 
 func main() {
-	cfg := NewConfig()
-
 	var cmd = &cobra.Command{
-		Use:   "aesgcm [encrypt|decrypt] INPUT_FILEPATH",
+		Use:   "aesgcm",
 		Short: "Encrypts or decrypts a file with password using AES-GCM algorithm",
 		Long: `Encrypts or decrypts a file with password using AES-GCM algorithm.
 
 Usage examples:
   aesgcm encrypt example.txt
-  aesgcm decrypt example.aes`,
-		Args: cobra.ExactArgs(2),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			action := args[0]
-			cfg.InputPath = args[1]
-			cmd.SilenceUsage = true
-			return runApp(
-				action,
-				cfg.InputPath,
-				cfg.OutputPath,
-				mapSessionCfg(cfg),
-				mapCodecCfg(cfg),
-				mapQREncoderCfg(cfg),
-				mapTerminalCfg(cfg),
-			)
-		},
-	}
-
-	cmd.Flags().StringVarP(&cfg.OutputPath, "output", "o", "",
-		fmt.Sprintf("Redirect output into the specified file. "+
-			"By default the output is saved at %q for encryption and %q for decryption.",
-			"INPUT_FILEPATH.aes", "INPUT_FILEPATH.txt"))
-
-	cmd.Flags().IntVarP(&cfg.MinPwdLength, "min-password-length", "p", DEFAULT_PWD_LENGTH,
-		"Minimum password length requirement. The password must be at least this many characters long.")
-
-	cmd.Flags().IntVar(&cfg.SaltLength, "salt-length", DEFAULT_SALT_LENGTH,
-		"Salt length. Salt is used to derive key from the password. Don't change unless you're absolutely confident.")
-	cmd.Flags().IntVar(&cfg.NonceLength, "nonce-length", DEFAULT_NONCE_LENGTH,
-		"Nonce length. Nonce is used together with the key to encrypt data and must be unique per given key. "+
-			"Don't change unless you're absolutely confident.")
-	cmd.Flags().IntVar(&cfg.KeyDerivationIterations, "key-derivation-iterations", DEFAULT_KEY_DERIVATION_ITERATIONS,
-		"Amount of iterations used to derive the key. Don't change unless you're absolutely confident.")
-	cmd.Flags().IntVar(&cfg.KeyDerivationLength, "key-derivation-length", DEFAULT_KEY_DERIVATION_LENGTH,
-		"Length of derived key. Don't change unless you're absolutely confident.")
-
-	cmd.Flags().BoolVar(&cfg.DisableBase64Processing, "disable-base64", false,
-		"Use Base64 encoding/decoding with the input/output DTO.")
-
-	cmd.Flags().BoolVar(&cfg.EnableQRGeneration, "qr-enable", false,
-		"Generate a PNG image with QR code alongside the encoded output.")
-	cmd.Flags().StringVar(&cfg.QRRecoveryLevel, "qr-level", DEFAULT_QR_RECOVERY_LEVEL,
-		"Set QR code error recovery level (low, medium, high, highest).")
-	cmd.Flags().IntVar(&cfg.QRSize, "qr-size", DEFAULT_QR_SIZE,
-		"Set QR code image size in pixels (if positive value provided). "+
-			"A negative value causes a variable sized image to be rendered "+
-			"(specified value will be applied as width in pixels for each QR code \"module\").")
-
-	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
-		if cfg.EnableQRGeneration {
-			return validateQRRecoveryLevel(cfg.QRRecoveryLevel)
-		}
-		return nil
+  aesgcm decrypt example.aes
+  aesgcm decrypt example.aes.png
+  aesgcm show seed.aes --per-line
+  aesgcm edit notes.aes
+  aesgcm exec secrets.env.aes -- ./server
+  aesgcm convert example.aes --format cbor -o example.cbor.aes
+  aesgcm paper example.aes --label "Wallet seed" -o backup.pdf
+  aesgcm qr-animate example.aes -o example.gif
+  aesgcm qr-receive frames/ -o example.aes
+  aesgcm stego embed --cover photo.png example.aes -o out.png
+  aesgcm keystore decrypt UTC--2024-01-01T00-00-00.000000000Z--7e5f4552091a69125d5dfcb7b8c2659029395bdf
+  aesgcm eip2335 verify keystore-m_12381_3600_0_0_0-1700000000.json`,
 	}
 
+	// every subcommand gets its own config, since flags write their defaults into it on registration
+	cmd.AddCommand(
+		newEncryptCmd(NewConfig()),
+		newDecryptCmd(NewConfig()),
+		newConvertCmd(NewConfig()),
+		newPaperCmd(NewConfig()),
+		newQRAnimateCmd(NewConfig()),
+		newQRReceiveCmd(NewConfig()),
+		newShowCmd(NewConfig()),
+		newEditCmd(NewConfig()),
+		newExecCmd(NewConfig()),
+		newStegoCmd(),
+		newKeystoreCmd(),
+		newEIP2335Cmd(),
+	)
+
 	if err := cmd.Execute(); err != nil {
 		os.Exit(1)
 	}
 }
 
-func runApp(
-	action string,
-	inputPath string,
-	outputPath string,
-	sessionCfg session.Config,
-	codecCfg codec.Config,
-	qrEncoderCfg qrencoder.QREncoderConfig,
-	terminalCfg terminal.Config) error {
-	terminal := terminal.NewTerminal(terminalCfg)
-	storage := filesystem.NewFileSystem()
+func newSession(cfg *Config) *session.Session {
+	terminal := terminal.NewTerminal(mapTerminalCfg(cfg))
+	storage := filesystem.NewFileSystem(mapFileSystemCfg(cfg))
 	aesgcm := aesgcm.NewAESGCM()
 	osRandomness := randomness.NewOSRandomness()
-	codec := codec.NewCodec(codecCfg, aesgcm, osRandomness)
-	qrEncoder := qrencoder.NewQREncoder(qrEncoderCfg)
-	session := session.NewSession(
-		sessionCfg,
+	compression := compression.NewCompression()
+	codec := codec.NewCodec(mapCodecCfg(cfg), aesgcm, osRandomness, compression)
+	container := container.NewContainer(mapContainerCfg(cfg))
+	qrEncoder := qrencoder.NewQREncoder(mapQREncoderCfg(cfg))
+	qrDecoder := qrdecoder.NewQRDecoder()
+	paperRenderer := paper.NewRenderer(mapPaperCfg(cfg))
+	fountain := fountain.NewFountain(mapFountainCfg(cfg))
+	stego := stego.NewStego()
+	keystore := keystore.NewKeystore(mapKeystoreCfg(cfg), osRandomness)
+	validatorKeystore := eip2335.NewKeystore(mapEIP2335Cfg(cfg), osRandomness)
+	return session.NewSession(
+		mapSessionCfg(cfg),
 		terminal,
 		storage,
 		codec,
+		container,
 		qrEncoder,
+		qrDecoder,
+		paperRenderer,
+		fountain,
+		stego,
+		keystore,
+		validatorKeystore,
+		wordlist.BIP39,
+		editor.NewEditor(mapEditorCfg(cfg)),
+		dotenv.NewDotenv(),
+		process.NewProcess(),
 	)
-
-	switch action {
-	case "encrypt":
-		if outputPath == "" {
-			outputPath = inputPath + ".aes"
-		}
-		return session.Encrypt(inputPath, outputPath)
-	case "decrypt":
-		if outputPath == "" {
-			outputPath = inputPath + ".txt"
-		}
-		return session.Decrypt(inputPath, outputPath)
-	default:
-		return fmt.Errorf("invalid action %q: "+
-			"please choose %q or %q", action, "encrypt", "decrypt")
-	}
 }
 
 func mapSessionCfg(cfg *Config) session.Config {
 	return session.Config{
-		Base64WrappingDisabled: cfg.DisableBase64Processing,
-		QRGenerationEnabled:    cfg.EnableQRGeneration,
+		QRGenerationEnabled:     cfg.EnableQRGeneration,
+		OverwriteEnabled:        cfg.Force,
+		MetadataDisabled:        cfg.DisableMetadata,
+		MetadataRestoreDisabled: cfg.DisableMetadataRestore,
+		MnemonicCheckEnabled:    cfg.BIP39 == "on",
+		MnemonicCheckDisabled:   cfg.BIP39 == "off",
+		ReformatEnabled:         cfg.Reformat,
+	}
+}
+
+func mapContainerCfg(cfg *Config) container.Config {
+	format := cfg.Format
+	if cfg.DisableBase64Processing {
+		format = container.FORMAT_JSON
+	}
+	return container.Config{
+		Format:       format,
+		ArmorEnabled: cfg.Armor,
+		ArmorComment: cfg.ArmorComment,
+		Encoding:     cfg.Encoding,
+		QREncoding:   cfg.QREncoding,
+		ECCParity:    cfg.ECCParity,
+	}
+}
+
+func mapFileSystemCfg(cfg *Config) filesystem.Config {
+	mode, _ := parseOutputMode(cfg.OutputMode)
+	return filesystem.Config{
+		Mode:             mode,
+		OverwriteEnabled: cfg.Force,
+		XattrsEnabled:    cfg.PreserveXattrs,
 	}
 }
 
@@ -151,7 +174,115 @@ func mapCodecCfg(cfg *Config) codec.Config {
 		KeyDerivationIterations: cfg.KeyDerivationIterations,
 		KeyDerivationLength:     cfg.KeyDerivationLength,
 		NonceLength:             cfg.NonceLength,
+		MetadataEncrypted:       cfg.EncryptMetadata,
+		Compression:             cfg.Compression,
+		MaxDecompressedSize:     cfg.MaxDecompressedSize,
+		Padding:                 cfg.Padding,
+	}
+}
+
+func mapEditorCfg(cfg *Config) editor.Config {
+	return editor.Config{
+		Command: cfg.Editor,
+		TmpDir:  cfg.EditTmpDir,
+	}
+}
+
+func validateCompression(algorithm string) error {
+	for _, supported := range CompressionAlgorithms {
+		if algorithm == supported {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid compression algorithm provided: %s", algorithm)
+}
+
+func validatePadding(scheme string) error {
+	if scheme == codec.PADDING_NONE {
+		return nil
+	}
+	if _, err := codec.ParsePadding(scheme); err != nil {
+		return fmt.Errorf("invalid padding scheme provided: %s", scheme)
+	}
+	return nil
+}
+
+func validateFormat(format string) error {
+	for _, supported := range container.Formats {
+		if format == supported {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid output format provided: %s", format)
+}
+
+func validateEncoding(encoding string, armor bool) error {
+	if encoding == "" {
+		return nil
+	}
+	if armor {
+		return fmt.Errorf("--encoding can't be combined with --armor")
+	}
+	for _, supported := range container.Encodings {
+		if encoding == supported {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid text encoding provided: %s", encoding)
+}
+
+func validateECC(parity int, armor bool, encoding string) error {
+	if parity == 0 {
+		return nil
+	}
+	if parity < 0 || parity > container.MAX_ECC_PARITY {
+		return fmt.Errorf("invalid error correction parity provided: %d (max %d)", parity, container.MAX_ECC_PARITY)
+	}
+	if !armor && encoding != container.ENCODING_BASE32 {
+		return fmt.Errorf("--ecc requires --armor or --encoding base32")
+	}
+	return nil
+}
+
+func mapKeystoreCfg(cfg *Config) keystore.Config {
+	return keystore.Config{
+		KDF:              cfg.KeystoreKDF,
+		ScryptN:          cfg.ScryptN,
+		PBKDF2Iterations: cfg.PBKDF2Iterations,
+	}
+}
+
+func mapEIP2335Cfg(cfg *Config) eip2335.Config {
+	return eip2335.Config{
+		KDF:              cfg.KeystoreKDF,
+		ScryptN:          cfg.ScryptN,
+		PBKDF2Iterations: cfg.PBKDF2Iterations,
+	}
+}
+
+func validateKeystoreKDF(kdf string, scryptN int, iterations int) error {
+	switch kdf {
+	case keystore.KDF_SCRYPT:
+		if scryptN <= 1 || scryptN&(scryptN-1) != 0 {
+			return fmt.Errorf("invalid scrypt cost provided: %d (must be a power of 2)", scryptN)
+		}
+		return nil
+	case keystore.KDF_PBKDF2:
+		if iterations <= 0 {
+			return fmt.Errorf("invalid PBKDF2 iterations provided: %d", iterations)
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid key derivation function provided: %s", kdf)
+}
+
+func validateBIP39Mode(mode string) error {
+	for _, supported := range BIP39Modes {
+		if mode == supported {
+			return nil
+		}
 	}
+	return fmt.Errorf("invalid BIP39 mode provided: %s", mode)
 }
 
 func validateQRRecoveryLevel(level string) error {
@@ -161,9 +292,94 @@ func validateQRRecoveryLevel(level string) error {
 	return nil
 }
 
+func validateOutputMode(mode string) error {
+	if _, err := parseOutputMode(mode); err != nil {
+		return fmt.Errorf("invalid output file mode provided: %s", mode)
+	}
+	return nil
+}
+
+func parseOutputMode(mode string) (os.FileMode, error) {
+	perm, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil {
+		return 0, err
+	}
+	if perm > 0777 {
+		return 0, fmt.Errorf("mode out of range: %s", mode)
+	}
+	return os.FileMode(perm), nil
+}
+
+func validatePaperSize(size string) error {
+	if _, ok := paper.PageSizes[size]; !ok {
+		return fmt.Errorf("invalid page size provided: %s", size)
+	}
+	return nil
+}
+
+func mapPaperCfg(cfg *Config) paper.Config {
+	return paper.Config{
+		PageSize: cfg.PaperSize,
+		QRLevel:  QRRecoveryLevels[cfg.QRRecoveryLevel],
+	}
+}
+
 func mapQREncoderCfg(cfg *Config) qrencoder.QREncoderConfig {
 	return qrencoder.QREncoderConfig{
 		Level: QRRecoveryLevels[cfg.QRRecoveryLevel],
 		Size:  cfg.QRSize,
+		Tiled: cfg.QRTiled,
+
+		AnimationFormat: cfg.AnimationFormat,
+		FrameDelay:      frameDelay(cfg.FrameRate),
+
+		Format:     cfg.QRFormat,
+		ModuleSize: cfg.QRModuleSize,
+		Inverted:   cfg.QRInverted,
+	}
+}
+
+func mapFountainCfg(cfg *Config) fountain.Config {
+	return fountain.Config{
+		BlockSize: cfg.FountainBlockSize,
+		Frames:    cfg.FountainFrames,
+	}
+}
+
+// frameDelay converts frame rate into frame duration in milliseconds
+func frameDelay(rate int) int {
+	if rate <= 0 {
+		return 0
+	}
+	return 1000 / rate
+}
+
+func validateQREncoding(encoding string) error {
+	for _, supported := range container.QREncodings {
+		if encoding == supported {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid QR code encoding provided: %s", encoding)
+}
+
+func validateQRFormat(format string, moduleSize float64) error {
+	if moduleSize <= 0 {
+		return fmt.Errorf("invalid QR code module size provided: %v", moduleSize)
+	}
+	for _, supported := range qrencoder.Formats {
+		if format == supported {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid QR code format provided: %s", format)
+}
+
+func validateAnimationFormat(format string) error {
+	for _, supported := range qrencoder.AnimationFormats {
+		if format == supported {
+			return nil
+		}
 	}
+	return fmt.Errorf("invalid animation format provided: %s", format)
 }
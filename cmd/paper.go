@@ -0,0 +1,62 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newPaperCmd(cfg *Config) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "paper INPUT_FILEPATH",
+		Short: "Renders a printable PDF backup sheet of an encrypted file",
+		Long: `Renders a printable PDF backup sheet of an encrypted file.
+The sheet contains a QR code, the armored text split into numbered lines with per-line checksums,
+key derivation parameters, a creation date, a label and recovery instructions.
+No password is required.`,
+		Args: cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputMode(cfg.OutputMode); err != nil {
+				return err
+			}
+			if err := validateFormat(cfg.Format); err != nil {
+				return err
+			}
+			if err := validatePaperSize(cfg.PaperSize); err != nil {
+				return err
+			}
+			if err := validateQREncoding(cfg.QREncoding); err != nil {
+				return err
+			}
+			if err := validateECC(cfg.ECCParity, true, ""); err != nil {
+				return err
+			}
+			return validateQRRecoveryLevel(cfg.QRRecoveryLevel)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg.InputPath = args[0]
+			cmd.SilenceUsage = true
+			if cfg.OutputPath == "" {
+				cfg.OutputPath = cfg.InputPath + ".pdf"
+			}
+			cfg.Armor = true
+			return newSession(cfg).Paper(cfg.InputPath, cfg.OutputPath, cfg.PaperLabel)
+		},
+	}
+
+	addOutputFlags(cmd, cfg, "Redirect output into the specified file. "+
+		"By default the output is saved at \"INPUT_FILEPATH.pdf\".")
+
+	cmd.Flags().StringVar(&cfg.PaperLabel, "label", "",
+		"Free-text label printed on the sheet.")
+	cmd.Flags().StringVar(&cfg.PaperSize, "page", DEFAULT_PAPER_SIZE,
+		"Page size (a4, letter).")
+	cmd.Flags().StringVar(&cfg.Format, "format", DEFAULT_PAPER_FORMAT,
+		"Format of the encrypted data inside the armor (json, cbor, binary).")
+	cmd.Flags().StringVar(&cfg.ArmorComment, "comment", "",
+		"Add a \"Comment\" header to the armor.")
+	addECCFlag(cmd, cfg)
+	cmd.Flags().StringVar(&cfg.QRRecoveryLevel, "qr-level", DEFAULT_QR_RECOVERY_LEVEL,
+		"Set QR code error recovery level (low, medium, high, highest).")
+	addQREncodingFlag(cmd, cfg)
+
+	return cmd
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/d347h-eth/aesgcm/internal/infra/qrencoder"
+
+	"github.com/spf13/cobra"
+)
+
+func newQRAnimateCmd(cfg *Config) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "qr-animate INPUT_FILEPATH",
+		Short: "Renders an encrypted file as an animation of fountain-coded QR codes",
+		Long: `Renders an encrypted file as a looped animation of fountain-coded QR codes
+for transferring it from an air-gapped machine with a camera.
+Every frame carries a combination of payload blocks (Luby transform code),
+so the file can be reconstructed from any sufficient subset of captured frames with qr-receive.
+No password is required.`,
+		Args: cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputMode(cfg.OutputMode); err != nil {
+				return err
+			}
+			if err := validateFormat(cfg.Format); err != nil {
+				return err
+			}
+			if cfg.FountainBlockSize <= 0 {
+				return fmt.Errorf("invalid block size provided: %d", cfg.FountainBlockSize)
+			}
+			if cfg.AnimationFormat == "" {
+				cfg.AnimationFormat = animationFormatByPath(cfg.OutputPath)
+			}
+			if err := validateAnimationFormat(cfg.AnimationFormat); err != nil {
+				return err
+			}
+			return validateQRRecoveryLevel(cfg.QRRecoveryLevel)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg.InputPath = args[0]
+			cmd.SilenceUsage = true
+			if cfg.OutputPath == "" {
+				cfg.OutputPath = cfg.InputPath + ".gif"
+				if cfg.AnimationFormat == qrencoder.ANIMATION_APNG {
+					cfg.OutputPath = cfg.InputPath + ".png"
+				}
+			}
+			return newSession(cfg).QRAnimate(cfg.InputPath, cfg.OutputPath)
+		},
+	}
+
+	addOutputFlags(cmd, cfg, "Redirect output into the specified file. "+
+		"By default the output is saved at \"INPUT_FILEPATH.gif\".")
+
+	cmd.Flags().StringVar(&cfg.AnimationFormat, "animation-format", "",
+		"Animation format (gif, apng). By default it's chosen by the output file extension, GIF otherwise.")
+	cmd.Flags().IntVar(&cfg.FrameRate, "fps", DEFAULT_FRAME_RATE,
+		"Number of frames per second.")
+	cmd.Flags().IntVar(&cfg.FountainBlockSize, "block-size", DEFAULT_FOUNTAIN_BLOCK_SIZE,
+		"Number of payload bytes per frame. Smaller blocks produce QR codes that are easier to capture.")
+	cmd.Flags().IntVar(&cfg.FountainFrames, "frames", 0,
+		"Number of frames in the animation, twice the number of blocks by default.")
+	cmd.Flags().StringVar(&cfg.Format, "format", DEFAULT_PAPER_FORMAT,
+		"Format of the transferred encrypted data (json, json-b64, cbor, binary).")
+	cmd.Flags().StringVar(&cfg.QRRecoveryLevel, "qr-level", DEFAULT_QR_RECOVERY_LEVEL,
+		"Set QR code error recovery level (low, medium, high, highest).")
+	cmd.Flags().IntVar(&cfg.QRSize, "qr-size", DEFAULT_ANIMATION_QR_SIZE,
+		"Set frame size in pixels.")
+
+	return cmd
+}
+
+// animationFormatByPath chooses APNG for ".png" and ".apng" output files
+func animationFormatByPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png", ".apng":
+		return qrencoder.ANIMATION_APNG
+	default:
+		return qrencoder.ANIMATION_GIF
+	}
+}
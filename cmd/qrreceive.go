@@ -0,0 +1,34 @@
+package main
+
+import (
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+func newQRReceiveCmd(cfg *Config) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "qr-receive FRAMES_PATH",
+		Short: "Reconstructs an encrypted file from captured frames of a QR code animation",
+		Long: `Reconstructs an encrypted file from captured frames of a QR code animation rendered by qr-animate.
+FRAMES_PATH is a directory of captured images (in any order, duplicates and unreadable frames are skipped)
+or an animated GIF. No password is required.`,
+		Args: cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return validateOutputMode(cfg.OutputMode)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg.InputPath = args[0]
+			cmd.SilenceUsage = true
+			if cfg.OutputPath == "" {
+				cfg.OutputPath = filepath.Clean(cfg.InputPath) + ".aes"
+			}
+			return newSession(cfg).QRReceive(cfg.InputPath, cfg.OutputPath)
+		},
+	}
+
+	addOutputFlags(cmd, cfg, "Redirect output into the specified file. "+
+		"By default the output is saved at \"FRAMES_PATH.aes\".")
+
+	return cmd
+}
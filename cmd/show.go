@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newShowCmd(cfg *Config) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "show [INPUT_FILEPATH] [QR_IMAGE_FILEPATH...]",
+		Short: "Reveals a decrypted secret on screen without writing a file",
+		Long: "Decrypts a file in memory and reveals the plaintext in the alternate screen buffer of the terminal, " +
+			"so it never reaches a file or the scrollback. The screen is cleared after --timeout or a keypress.\n\n" +
+			"With --per-line a single line is revealed at a time, a seed phrase written on one line is revealed word by word.",
+		Args: cobra.ArbitraryArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if cfg.ShowTimeout < 0 {
+				return fmt.Errorf("invalid timeout provided: %d", cfg.ShowTimeout)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return newSession(cfg).Show(args, time.Duration(cfg.ShowTimeout)*time.Second, cfg.ShowPerLine)
+		},
+	}
+
+	cmd.Flags().IntVar(&cfg.ShowTimeout, "timeout", DEFAULT_SHOW_TIMEOUT,
+		"Number of seconds the secret is shown for, in per-line mode since the last keypress. Zero disables the timeout.")
+	cmd.Flags().BoolVar(&cfg.ShowPerLine, "per-line", false,
+		"Reveal a single line at a time, a seed phrase written on one line is revealed word by word.")
+	cmd.Flags().Int64Var(&cfg.MaxDecompressedSize, "max-decompressed-size", DEFAULT_MAX_DECOMPRESSED_SIZE,
+		"Maximum size of decompressed plaintext in bytes, protects against decompression bombs.")
+
+	return cmd
+}
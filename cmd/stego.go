@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func newStegoCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "stego",
+		Short: "Hides an encrypted file inside an image",
+		Long: `Hides an encrypted file inside an image, so the backup doesn't look like one.
+The data is spread over the least significant bits of pixels chosen with a password,
+which may differ from the encryption password.`,
+	}
+	cmd.AddCommand(
+		newStegoEmbedCmd(NewConfig()),
+		newStegoExtractCmd(NewConfig()),
+	)
+	return cmd
+}
+
+func newStegoEmbedCmd(cfg *Config) *cobra.Command {
+	var coverPath string
+	var cmd = &cobra.Command{
+		Use:   "embed --cover COVER_FILEPATH INPUT_FILEPATH",
+		Short: "Hides an encrypted file inside a cover image",
+		Long: `Hides an encrypted file inside a cover image (PNG, JPEG or GIF), the output is always a PNG image.
+The cover has to hold 3 bits per pixel, e.g. a 1000x1000 photo holds about 370 KB.`,
+		Args: cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputMode(cfg.OutputMode); err != nil {
+				return err
+			}
+			return validateFormat(cfg.Format)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg.InputPath = args[0]
+			cmd.SilenceUsage = true
+			if cfg.OutputPath == "" {
+				cfg.OutputPath = cfg.InputPath + ".png"
+			}
+			return newSession(cfg).StegoEmbed(coverPath, cfg.InputPath, cfg.OutputPath)
+		},
+	}
+
+	addOutputFlags(cmd, cfg, fmt.Sprintf("Redirect output into the specified file. "+
+		"By default the output is saved at %q.", "INPUT_FILEPATH.png"))
+
+	cmd.Flags().StringVar(&coverPath, "cover", "",
+		"Cover image the encrypted file is hidden in.")
+	cmd.MarkFlagRequired("cover")
+	cmd.Flags().StringVar(&cfg.Format, "format", DEFAULT_STEGO_FORMAT,
+		"Format of the hidden encrypted data (json, cbor, binary).")
+	cmd.Flags().IntVarP(&cfg.MinPwdLength, "min-password-length", "p", DEFAULT_PWD_LENGTH,
+		"Minimum password length requirement. The password must be at least this many characters long.")
+
+	return cmd
+}
+
+func newStegoExtractCmd(cfg *Config) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "extract INPUT_FILEPATH",
+		Short: "Recovers an encrypted file hidden inside an image",
+		Args:  cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return validateOutputMode(cfg.OutputMode)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg.InputPath = args[0]
+			cmd.SilenceUsage = true
+			if cfg.OutputPath == "" {
+				cfg.OutputPath = cfg.InputPath + ".aes"
+			}
+			return newSession(cfg).StegoExtract(cfg.InputPath, cfg.OutputPath)
+		},
+	}
+
+	addOutputFlags(cmd, cfg, fmt.Sprintf("Redirect output into the specified file. "+
+		"By default the output is saved at %q.", "INPUT_FILEPATH.aes"))
+
+	return cmd
+}
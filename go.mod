@@ -10,10 +10,19 @@ require (
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1 // indirect
 )
 
 require (
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.2.0
+	github.com/fxamacker/cbor/v2 v2.5.0
+	github.com/kilic/bls12-381 v0.1.0
+	github.com/klauspost/compress v1.16.7
+	github.com/makiuchi-d/gozxing v0.1.1
 	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
 	github.com/spf13/cobra v1.7.0
-	golang.org/x/sys v0.11.0 // indirect
+	github.com/tyler-smith/go-bip39 v1.1.0
+	golang.org/x/sys v0.11.0
+	golang.org/x/text v0.12.0
 )
@@ -1,39 +1,96 @@
 package session
 
 import (
-	"encoding/base64"
-	"encoding/json"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/d347h-eth/aesgcm/internal/domain"
 )
 
+// SOURCE_FILE is the plaintext read from a file
+const SOURCE_FILE = "file"
+
+// SOURCE_PROMPT is the plaintext typed at a hidden terminal prompt
+const SOURCE_PROMPT = "prompt"
+
+// SOURCE_STDIN is the plaintext piped into stdin
+const SOURCE_STDIN = "stdin"
+
+// SOURCE_MNEMONIC is BIP39 mnemonic entered word by word
+const SOURCE_MNEMONIC = "mnemonic"
+
 type (
 	// Session is a component responsible for driving the core use case and user interaction
 	Session struct {
-		cfg          Config
-		terminal     Terminal
-		storage      Storage
-		codec        Codec
-		imageEncoder ImageEncoder
+		cfg           Config
+		terminal      Terminal
+		storage       Storage
+		codec         Codec
+		container     Container
+		imageEncoder  ImageEncoder
+		imageDecoder  ImageDecoder
+		paperRenderer PaperRenderer
+		fountain      Fountain
+		stego         Steganography
+		keystore      Keystore
+		// validatorKeystore handles EIP-2335 keystores of Ethereum validators
+		validatorKeystore ValidatorKeystore
+		mnemonic          Mnemonic
+		editor            Editor
+		dotenv            Dotenv
+		process           Process
 	}
 
 	// Config ...
 	Config struct {
-		Base64WrappingDisabled bool
-		QRGenerationEnabled    bool
+		QRGenerationEnabled bool
+		OverwriteEnabled    bool
+		// MetadataDisabled disables storing of the original file metadata on encryption
+		MetadataDisabled bool
+		// MetadataRestoreDisabled disables restoring of the original file name and attributes on decryption
+		MetadataRestoreDisabled bool
+		// MnemonicCheckEnabled forces validation of the plaintext as BIP39 mnemonic,
+		// otherwise only the plaintext which looks like a mnemonic is validated
+		MnemonicCheckEnabled bool
+		// MnemonicCheckDisabled disables validation of BIP39 mnemonics
+		MnemonicCheckDisabled bool
+		// ReformatEnabled allows the edited file to be saved in a format different from the original one
+		ReformatEnabled bool
 	}
 
 	// Terminal is a component responsible for receiving secrets from the user in real-time
 	Terminal interface {
 		ReceiveEncryptionPwd() ([]byte, error)
 		ReceiveDecryptionPwd() ([]byte, error)
+		ReceiveWords(hidden bool) ([]string, error)
+		ReceiveSecret() ([]byte, error)
+		ReceiveStdin() ([]byte, error)
+		Reveal(lines []string, timeout time.Duration, perLine bool) error
 	}
 
 	// Codec is a component responsible for encryption/decryption of data
 	Codec interface {
-		Encrypt(password []byte, plaintext []byte) (dto *domain.DTO, err error)
-		Decrypt(password []byte, dto *domain.DTO) (plaintext []byte, err error)
+		Encrypt(password []byte, plaintext []byte, metadata *domain.Metadata) (dto *domain.DTO, err error)
+		Decrypt(password []byte, dto *domain.DTO) (plaintext []byte, metadata *domain.Metadata, err error)
+		Open(password []byte, dto *domain.DTO) (opened *domain.Opened, err error)
+		Reencrypt(password []byte, plaintext []byte, metadata *domain.Metadata, previous *domain.DTO, opened *domain.Opened) (dto *domain.DTO, err error)
+	}
+
+	// Container is responsible for serialization of DTO and recognizing the format of the input
+	Container interface {
+		Marshal(dto *domain.DTO) ([]byte, error)
+		MarshalQR(dto *domain.DTO) ([]byte, error)
+		MarshalLines(dto *domain.DTO) ([]string, error)
+		Unmarshal(data []byte) (*domain.DTO, error)
+		Repair(data []byte) ([]byte, []domain.Repair, error)
 	}
 
 	// Storage is responsible for reading and writing of data
@@ -41,43 +98,128 @@ type (
 		ResourceExist(path string) bool
 		Read(path string) ([]byte, error)
 		Write(path string, data []byte) error
+		ReadMetadata(path string) (*domain.Metadata, error)
+		WriteMetadata(path string, metadata *domain.Metadata) error
+		ListFiles(path string) ([]string, error)
 	}
 
-	// ImageEncoder is responsible for encoding image data
+	// ImageEncoder is responsible for encoding data into QR code images,
+	// large payloads may be split across several images;
+	// empty extension means the images are text to be shown in the terminal instead of saving
 	ImageEncoder interface {
-		Encode(data []byte) ([]byte, error)
+		Extension() string
+		Encode(data []byte) ([][]byte, error)
+		EncodeAnimation(frames [][]byte) ([]byte, error)
+	}
+
+	// ImageDecoder is responsible for recognizing images and extracting QR code payloads from them
+	ImageDecoder interface {
+		IsImage(data []byte) bool
+		Decode(data []byte) ([][]byte, error)
+	}
+
+	// PaperRenderer is responsible for rendering printable paper backup sheets
+	PaperRenderer interface {
+		Render(sheet *domain.PaperSheet) ([]byte, error)
+	}
+
+	// Fountain is responsible for fountain coding of payloads transferred as animated QR codes
+	Fountain interface {
+		Encode(payload []byte) ([][]byte, error)
+		IsFrame(data []byte) bool
+		Decode(frames [][]byte) ([]byte, error)
+	}
+
+	// Steganography is responsible for hiding data inside cover images
+	Steganography interface {
+		Capacity(cover []byte) (int, error)
+		Embed(cover []byte, payload []byte, password []byte) ([]byte, error)
+		Extract(data []byte, password []byte) ([]byte, error)
+	}
+
+	// Keystore is responsible for encryption and decryption of Ethereum keystore files
+	Keystore interface {
+		Encrypt(privateKey []byte, password []byte) ([]byte, error)
+		Decrypt(data []byte, password []byte) ([]byte, error)
+		Address(privateKey []byte) (string, error)
+	}
+
+	// ValidatorKeystore is responsible for encryption and decryption of EIP-2335 keystore files of BLS validator keys
+	ValidatorKeystore interface {
+		Encrypt(key *domain.ValidatorKey, password []byte) ([]byte, error)
+		Decrypt(data []byte, password []byte) (*domain.ValidatorKey, error)
+	}
+
+	// Mnemonic is responsible for recognizing and validating BIP39 mnemonics
+	Mnemonic interface {
+		LooksLikeMnemonic(words []string) bool
+		ValidateMnemonic(words []string) error
+	}
+
+	// Editor is responsible for editing the plaintext with an external editor without writing it to disk
+	Editor interface {
+		Edit(plaintext []byte, name string) ([]byte, error)
+	}
+
+	// Dotenv is responsible for parsing of environment variables in dotenv syntax
+	Dotenv interface {
+		Parse(data []byte) ([]domain.EnvVar, error)
+	}
+
+	// Process is responsible for running commands with additional environment variables
+	Process interface {
+		Exec(args []string, vars []domain.EnvVar) error
 	}
 )
 
 // NewSession ...
-func NewSession(cfg Config, terminal Terminal, storage Storage, codec Codec, imgEncoder ImageEncoder) *Session {
-	return &Session{cfg, terminal, storage, codec, imgEncoder}
+func NewSession(
+	cfg Config,
+	terminal Terminal,
+	storage Storage,
+	codec Codec,
+	container Container,
+	imgEncoder ImageEncoder,
+	imgDecoder ImageDecoder,
+	paperRenderer PaperRenderer,
+	fountain Fountain,
+	stego Steganography,
+	keystore Keystore,
+	validatorKeystore ValidatorKeystore,
+	mnemonic Mnemonic,
+	editor Editor,
+	dotenv Dotenv,
+	process Process,
+) *Session {
+	return &Session{
+		cfg, terminal, storage, codec, container, imgEncoder, imgDecoder, paperRenderer, fountain, stego, keystore, validatorKeystore,
+		mnemonic, editor, dotenv, process,
+	}
 }
 
-// Encrypt ...
-func (s Session) Encrypt(inputPath string, outputPath string) error {
+// Encrypt encrypts the plaintext received from the source, the input path is used only by SOURCE_FILE
+func (s Session) Encrypt(source string, inputPath string, outputPath string) error {
 	// make sure the file with input plaintext exists
-	if !s.storage.ResourceExist(inputPath) {
+	if source == SOURCE_FILE && !s.storage.ResourceExist(inputPath) {
 		return fmt.Errorf("the file with plaintext input has not been found at: %q", inputPath)
 	}
 	// make sure the file with output ciphertext doesn't exist
-	if s.storage.ResourceExist(outputPath) {
+	if !s.cfg.OverwriteEnabled && s.storage.ResourceExist(outputPath) {
 		return fmt.Errorf("the file with ciphertext already exists at %q: "+
-			"specify different output path with -o flag or remove the file", outputPath)
+			"specify different output path with -o flag, remove the file or use --force", outputPath)
 	}
-	// make sure the image with QR code doesn't exist
-	outputQRImagePath := outputPath + ".png"
-	if s.cfg.QRGenerationEnabled {
-		if s.storage.ResourceExist(outputQRImagePath) {
-			return fmt.Errorf("the image with QR code already exists at %q: "+
-				"specify different output path with -o flag or remove the file", outputQRImagePath)
+	// make sure the images with QR codes don't exist, the number of parts is known only after encryption
+	extension := s.imageEncoder.Extension()
+	if s.cfg.QRGenerationEnabled && extension != "" {
+		if err := s.checkQRImageOutput([]string{outputPath + extension, outputPath + ".1" + extension}); err != nil {
+			return err
 		}
 	}
 
 	// receive input plaintext
-	plaintext, err := s.storage.Read(inputPath)
+	plaintext, metadata, err := s.readPlaintext(source, inputPath)
 	if err != nil {
-		return fmt.Errorf("failed to read input file: %w", err)
+		return err
 	}
 
 	// receive the password used to derive the key
@@ -87,23 +229,33 @@ func (s Session) Encrypt(inputPath string, outputPath string) error {
 	}
 
 	// encrypt
-	dto, err := s.codec.Encrypt(password, plaintext)
+	dto, err := s.codec.Encrypt(password, plaintext, metadata)
 	if err != nil {
 		return fmt.Errorf("encryption failed: %w", err)
 	}
 
 	// output encrypted data
-	outputJSON, err := json.Marshal(dto)
+	outputData, err := s.container.Marshal(dto)
 	if err != nil {
-		return fmt.Errorf("an error occurred while encoding to JSON: %w", err)
+		return fmt.Errorf("failed to serialize encrypted data: %w", err)
 	}
-	var outputData []byte
-	if s.cfg.Base64WrappingDisabled { // output JSON without Base64 encoding
-		outputData = outputJSON
-	} else { // wrap output JSON with additional Base64 encoding
-		tmpBuffer := make([]byte, base64.StdEncoding.EncodedLen(len(outputJSON)))
-		base64.StdEncoding.Encode(tmpBuffer, outputJSON)
-		outputData = tmpBuffer
+	var images [][]byte
+	var imagePaths []string
+	if s.cfg.QRGenerationEnabled {
+		qrData, err := s.container.MarshalQR(dto)
+		if err != nil {
+			return fmt.Errorf("failed to serialize encrypted data: %w", err)
+		}
+		images, err = s.imageEncoder.Encode(qrData)
+		if err != nil {
+			return fmt.Errorf("failed to encode output image: %w", err)
+		}
+		if extension != "" {
+			imagePaths = qrImagePaths(outputPath, extension, len(images))
+			if err := s.checkQRImageOutput(imagePaths); err != nil {
+				return err
+			}
+		}
 	}
 	err = s.storage.Write(outputPath, outputData)
 	if err != nil {
@@ -111,56 +263,152 @@ func (s Session) Encrypt(inputPath string, outputPath string) error {
 	}
 	fmt.Printf("Successfully encrypted to %q\n", outputPath)
 
-	// output QR code image
-	if s.cfg.QRGenerationEnabled {
-		imgBytes, err := s.imageEncoder.Encode(outputData)
-		if err != nil {
-			return fmt.Errorf("failed to encode output image: %w", err)
+	// output QR code images
+	for i, imgBytes := range images {
+		if extension == "" {
+			if len(images) > 1 {
+				fmt.Printf("QR code %d of %d:\n", i+1, len(images))
+			}
+			fmt.Print(string(imgBytes))
+			continue
 		}
-		err = s.storage.Write(outputQRImagePath, imgBytes)
+		err = s.storage.Write(imagePaths[i], imgBytes)
 		if err != nil {
 			return fmt.Errorf("failed to save output image: %w", err)
 		}
-		fmt.Printf("QR code saved to %q\n", outputQRImagePath)
+		fmt.Printf("QR code saved to %q\n", imagePaths[i])
 	}
 	return nil
 }
 
-// Decrypt ...
-func (s Session) Decrypt(inputPath string, outputPath string) error {
-	// make sure the file with input ciphertext exists
-	if !s.storage.ResourceExist(inputPath) {
-		return fmt.Errorf("the file with ciphertext input has not been found at: %q", inputPath)
+// readPlaintext receives the plaintext from the source, only files have metadata;
+// the plaintext which looks like BIP39 mnemonic is validated
+func (s Session) readPlaintext(source string, inputPath string) ([]byte, *domain.Metadata, error) {
+	var plaintext []byte
+	var err error
+	switch source {
+	case SOURCE_FILE:
+		plaintext, err = s.storage.Read(inputPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read input file: %w", err)
+		}
+	case SOURCE_PROMPT:
+		plaintext, err = s.terminal.ReceiveSecret()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to receive the secret: %w", err)
+		}
+	case SOURCE_STDIN:
+		plaintext, err = s.terminal.ReceiveStdin()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to receive the secret: %w", err)
+		}
+	case SOURCE_MNEMONIC:
+		words, err := s.terminal.ReceiveWords(true)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to receive the words: %w", err)
+		}
+		if err := s.mnemonic.ValidateMnemonic(words); err != nil {
+			return nil, nil, fmt.Errorf("invalid BIP39 mnemonic: %w", err)
+		}
+		fmt.Printf("Valid BIP39 mnemonic of %d words\n", len(words))
+		return []byte(strings.ToLower(strings.Join(words, " ")) + "\n"), nil, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown plaintext source: %q", source)
 	}
-	// make sure the file with output plaintext doesn't exist
-	if s.storage.ResourceExist(outputPath) {
-		return fmt.Errorf("the file with plaintext already exists at %q: "+
-			"specify different output path with -o flag or remove the file",
-			outputPath)
+	if err := s.checkMnemonic(plaintext); err != nil {
+		return nil, nil, err
 	}
 
-	// process the input
-	inputData, err := s.storage.Read(inputPath)
+	if source != SOURCE_FILE || s.cfg.MetadataDisabled {
+		return plaintext, nil, nil
+	}
+	metadata, err := s.storage.ReadMetadata(inputPath)
 	if err != nil {
-		return fmt.Errorf("failed to read input file: %w", err)
+		return nil, nil, fmt.Errorf("failed to read input file metadata: %w", err)
 	}
-	var inputJson []byte
-	if s.cfg.Base64WrappingDisabled { // input is already JSON
-		inputJson = inputData
-	} else { // decode Base64 wrapping first
-		tmpBuffer := make([]byte, base64.StdEncoding.DecodedLen(len(inputData)))
-		bytesWritten, err := base64.StdEncoding.Decode(tmpBuffer, inputData)
-		if err != nil {
-			return fmt.Errorf("failed to decode Base64 data from input file: %w", err)
+	return plaintext, metadata, nil
+}
+
+// checkMnemonic validates the plaintext which looks like BIP39 mnemonic (or any plaintext if the check is forced),
+// so a mistyped word is caught before the seed phrase is encrypted
+func (s Session) checkMnemonic(plaintext []byte) error {
+	if s.cfg.MnemonicCheckDisabled {
+		return nil
+	}
+	words := strings.Fields(string(plaintext))
+	if !s.cfg.MnemonicCheckEnabled && !s.mnemonic.LooksLikeMnemonic(words) {
+		return nil
+	}
+	if err := s.mnemonic.ValidateMnemonic(words); err != nil {
+		return fmt.Errorf("the plaintext is not a valid BIP39 mnemonic: %w (use --bip39=off to encrypt it anyway)", err)
+	}
+	fmt.Printf("Valid BIP39 mnemonic of %d words\n", len(words))
+	return nil
+}
+
+// recheckMnemonic warns if the decrypted plaintext looks like BIP39 mnemonic but its checksum doesn't match,
+// the plaintext is written anyway since it's exactly what has been encrypted
+func (s Session) recheckMnemonic(plaintext []byte) {
+	if s.cfg.MnemonicCheckDisabled {
+		return
+	}
+	words := strings.Fields(string(plaintext))
+	if !s.cfg.MnemonicCheckEnabled && !s.mnemonic.LooksLikeMnemonic(words) {
+		return
+	}
+	if err := s.mnemonic.ValidateMnemonic(words); err != nil {
+		fmt.Printf("Warning: the decrypted plaintext is not a valid BIP39 mnemonic: %s\n", err)
+	}
+}
+
+// qrImagePaths returns "OUTPUT.png" for a single image or "OUTPUT.1.png … OUTPUT.N.png" for a split payload
+func qrImagePaths(outputPath string, extension string, count int) []string {
+	if count == 1 {
+		return []string{outputPath + extension}
+	}
+	paths := make([]string, count)
+	for i := range paths {
+		paths[i] = fmt.Sprintf("%s.%d%s", outputPath, i+1, extension)
+	}
+	return paths
+}
+
+func (s Session) checkQRImageOutput(paths []string) error {
+	if s.cfg.OverwriteEnabled {
+		return nil
+	}
+	for _, path := range paths {
+		if s.storage.ResourceExist(path) {
+			return fmt.Errorf("the image with QR code already exists at %q: "+
+				"specify different output path with -o flag, remove the file or use --force", path)
 		}
-		inputJson = tmpBuffer[:bytesWritten]
 	}
+	return nil
+}
 
-	// unmarshal JSON data into DTO type
-	dto := &domain.DTO{}
-	err = json.Unmarshal(inputJson, dto)
+// Decrypt decrypts the input file (or a set of QR code images), if the output path is empty it's derived
+// from the original file name stored in metadata (or the first input path)
+func (s Session) Decrypt(inputPaths []string, outputPath string) error {
+	if len(inputPaths) == 0 && outputPath == "" {
+		return fmt.Errorf("output path has to be specified with -o flag when the words are entered interactively")
+	}
+	// make sure the files with input ciphertext exist
+	for _, inputPath := range inputPaths {
+		if !s.storage.ResourceExist(inputPath) {
+			return fmt.Errorf("the file with ciphertext input has not been found at: %q", inputPath)
+		}
+	}
+	// make sure the file with output plaintext doesn't exist
+	if outputPath != "" {
+		if err := s.checkPlaintextOutput(outputPath); err != nil {
+			return err
+		}
+	}
+
+	// process the input
+	dto, err := s.readCiphertext(inputPaths...)
 	if err != nil {
-		return fmt.Errorf("failed to unmarshal JSON from input file: %w", err)
+		return err
 	}
 
 	// receive the password used to derive the key
@@ -170,10 +418,20 @@ func (s Session) Decrypt(inputPath string, outputPath string) error {
 	}
 
 	// decrypt
-	plaintext, err := s.codec.Decrypt(password, dto)
+	plaintext, metadata, err := s.codec.Decrypt(password, dto)
 	if err != nil {
 		return fmt.Errorf("decryption failed: %w", err)
 	}
+	if s.cfg.MetadataRestoreDisabled {
+		metadata = nil
+	}
+	s.recheckMnemonic(plaintext)
+	if outputPath == "" {
+		outputPath = defaultPlaintextPath(inputPaths[0], metadata)
+		if err := s.checkPlaintextOutput(outputPath); err != nil {
+			return err
+		}
+	}
 
 	// output plaintext
 	err = s.storage.Write(outputPath, plaintext)
@@ -181,5 +439,792 @@ func (s Session) Decrypt(inputPath string, outputPath string) error {
 		return fmt.Errorf("failed to save plaintext output: %w", err)
 	}
 	fmt.Printf("Successfully decrypted to %q\n", outputPath)
+
+	// restore original attributes
+	if metadata != nil {
+		if err := s.storage.WriteMetadata(outputPath, metadata); err != nil {
+			return fmt.Errorf("failed to restore file metadata: %w", err)
+		}
+	}
+	return nil
+}
+
+// Show decrypts the input in memory and reveals the plaintext in the terminal, nothing is written to disk;
+// in per-line mode a single-line BIP39 mnemonic is revealed word by word
+func (s Session) Show(inputPaths []string, timeout time.Duration, perLine bool) error {
+	// make sure the files with input ciphertext exist
+	for _, inputPath := range inputPaths {
+		if !s.storage.ResourceExist(inputPath) {
+			return fmt.Errorf("the file with ciphertext input has not been found at: %q", inputPath)
+		}
+	}
+	dto, err := s.readCiphertext(inputPaths...)
+	if err != nil {
+		return err
+	}
+	password, err := s.terminal.ReceiveDecryptionPwd()
+	if err != nil {
+		return fmt.Errorf("failed to receive a password: %w", err)
+	}
+	plaintext, _, err := s.codec.Decrypt(password, dto)
+	if err != nil {
+		return fmt.Errorf("decryption failed: %w", err)
+	}
+
+	// control characters could be interpreted by the terminal, such plaintext has to be decrypted into a file
+	text := strings.ReplaceAll(string(plaintext), "\r\n", "\n")
+	for _, r := range text {
+		if r == utf8.RuneError || unicode.IsControl(r) && r != '\n' && r != '\t' {
+			return fmt.Errorf("the plaintext isn't printable text: decrypt it into a file instead")
+		}
+	}
+	s.recheckMnemonic(plaintext)
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if words := strings.Fields(text); perLine && len(lines) == 1 && s.mnemonic.LooksLikeMnemonic(words) {
+		lines = words
+	}
+	return s.terminal.Reveal(lines, timeout, perLine)
+}
+
+// Edit decrypts the file into the editor and re-encrypts it in place if the plaintext has been changed,
+// the same password is used and the key derivation parameters are kept unless stronger ones are configured;
+// the original file is left untouched if the editor fails
+func (s Session) Edit(inputPath string) error {
+	// make sure the file with input ciphertext exists
+	if !s.storage.ResourceExist(inputPath) {
+		return fmt.Errorf("the file with ciphertext input has not been found at: %q", inputPath)
+	}
+	inputData, err := s.storage.Read(inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to read input file: %w", err)
+	}
+	dto, err := s.container.Unmarshal(inputData)
+	if err != nil {
+		return fmt.Errorf("failed to deserialize encrypted data: %w", err)
+	}
+	// the file is rewritten with the configured format, which has to reproduce the original one
+	if !s.cfg.ReformatEnabled {
+		outputData, err := s.container.Marshal(dto)
+		if err != nil {
+			return fmt.Errorf("failed to serialize encrypted data: %w", err)
+		}
+		if !bytes.Equal(bytes.TrimSpace(outputData), bytes.TrimSpace(inputData)) {
+			return fmt.Errorf("the file isn't in the configured output format: " +
+				"specify the format flags it has been encrypted with or use --reformat to convert it")
+		}
+	}
+	attributes, err := s.storage.ReadMetadata(inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to read input file metadata: %w", err)
+	}
+
+	password, err := s.terminal.ReceiveDecryptionPwd()
+	if err != nil {
+		return fmt.Errorf("failed to receive a password: %w", err)
+	}
+	opened, err := s.codec.Open(password, dto)
+	if err != nil {
+		return fmt.Errorf("decryption failed: %w", err)
+	}
+	plaintext, metadata := opened.Plaintext, opened.Metadata
+	name := strings.TrimSuffix(filepath.Base(inputPath), ".aes")
+	if metadata != nil && metadata.Name != "" {
+		name = metadata.Name
+	}
+	edited, err := s.editor.Edit(plaintext, name)
+	if err != nil {
+		return fmt.Errorf("the file has been left unchanged: %w", err)
+	}
+	if sha256.Sum256(edited) == sha256.Sum256(plaintext) {
+		fmt.Println("No changes have been made")
+		return nil
+	}
+	s.recheckMnemonic(edited)
+
+	// re-encrypt with the same password, a fresh salt and nonce
+	if metadata != nil {
+		metadata.ModTime = time.Now().UTC()
+	}
+	dto, err = s.codec.Reencrypt(password, edited, metadata, dto, opened)
+	if err != nil {
+		return fmt.Errorf("encryption failed: %w", err)
+	}
+	outputData, err := s.container.Marshal(dto)
+	if err != nil {
+		return fmt.Errorf("failed to serialize encrypted data: %w", err)
+	}
+	if err := s.storage.Write(inputPath, outputData); err != nil {
+		return fmt.Errorf("failed to save encrypted data: %w", err)
+	}
+	attributes.ModTime = time.Now()
+	if err := s.storage.WriteMetadata(inputPath, attributes); err != nil {
+		return fmt.Errorf("failed to restore file metadata: %w", err)
+	}
+	fmt.Printf("Successfully re-encrypted %q\n", inputPath)
+	return nil
+}
+
+// Exec decrypts the dotenv file in memory and runs the command with the variables merged into its environment,
+// only the listed variables are passed if the list isn't empty; on success it never returns
+func (s Session) Exec(inputPath string, args []string, only []string) error {
+	// make sure the file with input ciphertext exists
+	if !s.storage.ResourceExist(inputPath) {
+		return fmt.Errorf("the file with ciphertext input has not been found at: %q", inputPath)
+	}
+	dto, err := s.readCiphertext(inputPath)
+	if err != nil {
+		return err
+	}
+	password, err := s.terminal.ReceiveDecryptionPwd()
+	if err != nil {
+		return fmt.Errorf("failed to receive a password: %w", err)
+	}
+	plaintext, _, err := s.codec.Decrypt(password, dto)
+	if err != nil {
+		return fmt.Errorf("decryption failed: %w", err)
+	}
+	vars, err := s.dotenv.Parse(plaintext)
+	if err != nil {
+		return fmt.Errorf("failed to parse environment variables: %w", err)
+	}
+	if len(only) > 0 {
+		defined := make(map[string]domain.EnvVar, len(vars))
+		for _, v := range vars {
+			defined[v.Name] = v
+		}
+		vars = vars[:0]
+		for _, name := range only {
+			v, ok := defined[name]
+			if !ok {
+				return fmt.Errorf("the variable %q isn't defined in %q", name, inputPath)
+			}
+			vars = append(vars, v)
+		}
+	}
+	return s.process.Exec(args, vars)
+}
+
+// readCiphertext reads the input files, recognizes their format and deserializes DTO,
+// images are scanned for QR codes and the payload is processed as if it was read from a file,
+// multiple images (or codes within an image) are reassembled from their sequence headers in any order;
+// without input files the ciphertext encoded with words is entered in the terminal
+func (s Session) readCiphertext(inputPaths ...string) (*domain.DTO, error) {
+	var payloads [][]byte
+	if len(inputPaths) == 0 {
+		words, err := s.terminal.ReceiveWords(false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to receive the words: %w", err)
+		}
+		payloads = append(payloads, []byte(strings.Join(words, " ")))
+	}
+	for _, inputPath := range inputPaths {
+		inputData, err := s.storage.Read(inputPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input file: %w", err)
+		}
+		if !s.imageDecoder.IsImage(inputData) {
+			if len(inputPaths) > 1 {
+				return nil, fmt.Errorf("multiple input files are supported only for QR code images: %q is not an image", inputPath)
+			}
+			payloads = append(payloads, inputData)
+			continue
+		}
+		decoded, err := s.imageDecoder.Decode(inputData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan QR code in %q: %w", inputPath, err)
+		}
+		payloads = append(payloads, decoded...)
+	}
+	inputData, err := joinPayloads(payloads)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reassemble QR code payload: %w", err)
+	}
+	inputData, repairs, err := s.container.Repair(inputData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to repair input file: %w", err)
+	}
+	reportRepairs(repairs)
+	dto, err := s.container.Unmarshal(inputData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse input file: %w", err)
+	}
+	return dto, nil
+}
+
+// reportRepairs prints the lines corrected with error correction code, so the backup copy can be fixed
+// before the damage grows beyond repair
+func reportRepairs(repairs []domain.Repair) {
+	if len(repairs) == 0 {
+		return
+	}
+	total := 0
+	lines := make([]string, len(repairs))
+	for i, repair := range repairs {
+		total += repair.Bytes
+		lines[i] = strconv.Itoa(repair.Line)
+	}
+	noun := "line"
+	if len(lines) > 1 {
+		noun = "lines"
+	}
+	fmt.Printf("Repaired %d damaged bytes in %s %s with error correction code, fix them in the backup copy\n",
+		total, noun, strings.Join(lines, ", "))
+}
+
+// joinPayloads reassembles the payload split across several QR codes, payloads without sequence headers
+// are joined with line breaks, since text formats like multipart UR carry their own sequencing
+func joinPayloads(payloads [][]byte) ([]byte, error) {
+	var parts []domain.QRPart
+	for _, payload := range payloads {
+		part, ok, err := domain.ParseQRPart(payload)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			parts = append(parts, *part)
+		}
+	}
+	switch {
+	case len(parts) == 0:
+		return bytes.Join(payloads, []byte("\n")), nil
+	case len(parts) < len(payloads):
+		return nil, fmt.Errorf("found %d QR codes, but not all of them are parts of a split payload", len(payloads))
+	}
+	return domain.JoinQRParts(parts)
+}
+
+func (s Session) checkPlaintextOutput(outputPath string) error {
+	if !s.cfg.OverwriteEnabled && s.storage.ResourceExist(outputPath) {
+		return fmt.Errorf("the file with plaintext already exists at %q: "+
+			"specify different output path with -o flag, remove the file or use --force",
+			outputPath)
+	}
+	return nil
+}
+
+// defaultPlaintextPath places the file with its original name next to the input file,
+// the name is untrusted input, so anything but a plain file name is ignored
+func defaultPlaintextPath(inputPath string, metadata *domain.Metadata) string {
+	if metadata != nil {
+		name := metadata.Name
+		if name != "" && name != "." && name != ".." && name == filepath.Base(name) && !strings.ContainsAny(name, `/\`) {
+			return filepath.Join(filepath.Dir(inputPath), name)
+		}
+	}
+	return inputPath + ".txt"
+}
+
+// Convert re-serializes the input file into the configured output format,
+// the ciphertext is moved as is, so no password is required
+func (s Session) Convert(inputPath string, outputPath string) error {
+	// make sure the file with input ciphertext exists
+	if !s.storage.ResourceExist(inputPath) {
+		return fmt.Errorf("the file with ciphertext input has not been found at: %q", inputPath)
+	}
+	// make sure the output file doesn't exist
+	if !s.cfg.OverwriteEnabled && s.storage.ResourceExist(outputPath) {
+		return fmt.Errorf("the file with ciphertext already exists at %q: "+
+			"specify different output path with -o flag, remove the file or use --force", outputPath)
+	}
+
+	dto, err := s.readCiphertext(inputPath)
+	if err != nil {
+		return err
+	}
+	outputData, err := s.container.Marshal(dto)
+	if err != nil {
+		return fmt.Errorf("failed to serialize encrypted data: %w", err)
+	}
+	err = s.storage.Write(outputPath, outputData)
+	if err != nil {
+		return fmt.Errorf("failed to save converted data: %w", err)
+	}
+	fmt.Printf("Successfully converted to %q\n", outputPath)
+	return nil
+}
+
+// Paper renders a printable backup sheet of the encrypted file, no password is required
+func (s Session) Paper(inputPath string, outputPath string, label string) error {
+	// make sure the file with input ciphertext exists
+	if !s.storage.ResourceExist(inputPath) {
+		return fmt.Errorf("the file with ciphertext input has not been found at: %q", inputPath)
+	}
+	// make sure the output file doesn't exist
+	if !s.cfg.OverwriteEnabled && s.storage.ResourceExist(outputPath) {
+		return fmt.Errorf("the paper backup already exists at %q: "+
+			"specify different output path with -o flag, remove the file or use --force", outputPath)
+	}
+
+	dto, err := s.readCiphertext(inputPath)
+	if err != nil {
+		return err
+	}
+	qrData, err := s.container.MarshalQR(dto)
+	if err != nil {
+		return fmt.Errorf("failed to serialize encrypted data: %w", err)
+	}
+	lines, err := s.container.MarshalLines(dto)
+	if err != nil {
+		return fmt.Errorf("failed to serialize encrypted data: %w", err)
+	}
+	sheet := &domain.PaperSheet{
+		Label:         label,
+		Created:       time.Now(),
+		KeyDerivation: dto.KeyDerivation,
+		NonceLength:   len(dto.Nonce),
+		Lines:         lines,
+		QRData:        qrData,
+	}
+	document, err := s.paperRenderer.Render(sheet)
+	if err != nil {
+		return fmt.Errorf("failed to render paper backup: %w", err)
+	}
+	err = s.storage.Write(outputPath, document)
+	if err != nil {
+		return fmt.Errorf("failed to save paper backup: %w", err)
+	}
+	fmt.Printf("Paper backup saved to %q\n", outputPath)
+	return nil
+}
+
+// QRAnimate renders the encrypted file as a looped animation of fountain-coded QR codes,
+// the receiver can reconstruct it from any sufficient subset of frames, no password is required
+func (s Session) QRAnimate(inputPath string, outputPath string) error {
+	// make sure the file with input ciphertext exists
+	if !s.storage.ResourceExist(inputPath) {
+		return fmt.Errorf("the file with ciphertext input has not been found at: %q", inputPath)
+	}
+	// make sure the output file doesn't exist
+	if !s.cfg.OverwriteEnabled && s.storage.ResourceExist(outputPath) {
+		return fmt.Errorf("the animation already exists at %q: "+
+			"specify different output path with -o flag, remove the file or use --force", outputPath)
+	}
+
+	dto, err := s.readCiphertext(inputPath)
+	if err != nil {
+		return err
+	}
+	payload, err := s.container.Marshal(dto)
+	if err != nil {
+		return fmt.Errorf("failed to serialize encrypted data: %w", err)
+	}
+	frames, err := s.fountain.Encode(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode frames: %w", err)
+	}
+	animation, err := s.imageEncoder.EncodeAnimation(frames)
+	if err != nil {
+		return fmt.Errorf("failed to encode animation: %w", err)
+	}
+	err = s.storage.Write(outputPath, animation)
+	if err != nil {
+		return fmt.Errorf("failed to save animation: %w", err)
+	}
+	fmt.Printf("Animation with %d frames saved to %q\n", len(frames), outputPath)
+	return nil
+}
+
+// QRReceive reconstructs the encrypted file from captured frames of a QR code animation,
+// the input is a directory of images (or a single animated GIF), files without frames are skipped
+func (s Session) QRReceive(inputPath string, outputPath string) error {
+	// make sure the captured frames exist
+	if !s.storage.ResourceExist(inputPath) {
+		return fmt.Errorf("the captured frames have not been found at: %q", inputPath)
+	}
+	// make sure the output file doesn't exist
+	if !s.cfg.OverwriteEnabled && s.storage.ResourceExist(outputPath) {
+		return fmt.Errorf("the file with ciphertext already exists at %q: "+
+			"specify different output path with -o flag, remove the file or use --force", outputPath)
+	}
+
+	files, err := s.storage.ListFiles(inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to list captured frames: %w", err)
+	}
+	var frames [][]byte
+	for _, file := range files {
+		data, err := s.storage.Read(file)
+		if err != nil {
+			return fmt.Errorf("failed to read input file: %w", err)
+		}
+		if !s.imageDecoder.IsImage(data) {
+			continue
+		}
+		payloads, err := s.imageDecoder.Decode(data)
+		if err != nil {
+			continue
+		}
+		for _, payload := range payloads {
+			if s.fountain.IsFrame(payload) {
+				frames = append(frames, payload)
+			}
+		}
+	}
+	fmt.Printf("Scanned %d frames from %d files\n", len(frames), len(files))
+
+	payload, err := s.fountain.Decode(frames)
+	if err != nil {
+		return fmt.Errorf("failed to reconstruct encrypted data: %w", err)
+	}
+	// make sure the reconstructed payload is a valid encrypted file
+	if _, err := s.container.Unmarshal(payload); err != nil {
+		return fmt.Errorf("failed to parse reconstructed data: %w", err)
+	}
+	err = s.storage.Write(outputPath, payload)
+	if err != nil {
+		return fmt.Errorf("failed to save received data: %w", err)
+	}
+	fmt.Printf("Successfully received to %q\n", outputPath)
+	return nil
+}
+
+// StegoEmbed hides the encrypted file inside the cover image, the input is re-serialized into
+// the configured (compact) format and its size is checked against the capacity of the cover before embedding
+func (s Session) StegoEmbed(coverPath string, inputPath string, outputPath string) error {
+	// make sure the input files exist
+	if !s.storage.ResourceExist(inputPath) {
+		return fmt.Errorf("the file with ciphertext input has not been found at: %q", inputPath)
+	}
+	if !s.storage.ResourceExist(coverPath) {
+		return fmt.Errorf("the cover image has not been found at: %q", coverPath)
+	}
+	// make sure the output file doesn't exist
+	if !s.cfg.OverwriteEnabled && s.storage.ResourceExist(outputPath) {
+		return fmt.Errorf("the image already exists at %q: "+
+			"specify different output path with -o flag, remove the file or use --force", outputPath)
+	}
+
+	dto, err := s.readCiphertext(inputPath)
+	if err != nil {
+		return err
+	}
+	payload, err := s.container.Marshal(dto)
+	if err != nil {
+		return fmt.Errorf("failed to serialize encrypted data: %w", err)
+	}
+	cover, err := s.storage.Read(coverPath)
+	if err != nil {
+		return fmt.Errorf("failed to read cover image: %w", err)
+	}
+	capacity, err := s.stego.Capacity(cover)
+	if err != nil {
+		return err
+	}
+	if len(payload) > capacity {
+		return fmt.Errorf("the cover image is too small: it can hold %d bytes, the encrypted data is %d bytes", capacity, len(payload))
+	}
+
+	// receive the password used to choose the pixels carrying the data
+	password, err := s.terminal.ReceiveEncryptionPwd()
+	if err != nil {
+		return fmt.Errorf("failed to receive a password: %w", err)
+	}
+	output, err := s.stego.Embed(cover, payload, password)
+	if err != nil {
+		return fmt.Errorf("failed to embed encrypted data: %w", err)
+	}
+	err = s.storage.Write(outputPath, output)
+	if err != nil {
+		return fmt.Errorf("failed to save output image: %w", err)
+	}
+	fmt.Printf("Successfully embedded %d bytes (%.1f%% of the capacity) to %q\n",
+		len(payload), float64(len(payload))*100/float64(capacity), outputPath)
+	return nil
+}
+
+// StegoExtract recovers the encrypted file hidden inside the image
+func (s Session) StegoExtract(inputPath string, outputPath string) error {
+	// make sure the image exists
+	if !s.storage.ResourceExist(inputPath) {
+		return fmt.Errorf("the image has not been found at: %q", inputPath)
+	}
+	// make sure the output file doesn't exist
+	if !s.cfg.OverwriteEnabled && s.storage.ResourceExist(outputPath) {
+		return fmt.Errorf("the file with ciphertext already exists at %q: "+
+			"specify different output path with -o flag, remove the file or use --force", outputPath)
+	}
+
+	data, err := s.storage.Read(inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to read input file: %w", err)
+	}
+	// receive the password used to choose the pixels carrying the data
+	password, err := s.terminal.ReceiveDecryptionPwd()
+	if err != nil {
+		return fmt.Errorf("failed to receive a password: %w", err)
+	}
+	payload, err := s.stego.Extract(data, password)
+	if err != nil {
+		return fmt.Errorf("failed to extract encrypted data: %w", err)
+	}
+	// make sure the extracted payload is a valid encrypted file
+	if _, err := s.container.Unmarshal(payload); err != nil {
+		return fmt.Errorf("failed to parse extracted data: %w", err)
+	}
+	err = s.storage.Write(outputPath, payload)
+	if err != nil {
+		return fmt.Errorf("failed to save extracted data: %w", err)
+	}
+	fmt.Printf("Successfully extracted to %q\n", outputPath)
+	return nil
+}
+
+// KeystoreDecrypt decrypts the Ethereum keystore file and encrypts the private key (hex) into the container
+// with the same password, the address is printed so the key can be verified against the wallet
+func (s Session) KeystoreDecrypt(inputPath string, outputPath string) error {
+	// make sure the keystore file exists
+	if !s.storage.ResourceExist(inputPath) {
+		return fmt.Errorf("the keystore file has not been found at: %q", inputPath)
+	}
+	// make sure the file with output ciphertext doesn't exist
+	if !s.cfg.OverwriteEnabled && s.storage.ResourceExist(outputPath) {
+		return fmt.Errorf("the file with ciphertext already exists at %q: "+
+			"specify different output path with -o flag, remove the file or use --force", outputPath)
+	}
+
+	data, err := s.storage.Read(inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to read input file: %w", err)
+	}
+	// receive the keystore password, it's reused for the output
+	password, err := s.terminal.ReceiveDecryptionPwd()
+	if err != nil {
+		return fmt.Errorf("failed to receive a password: %w", err)
+	}
+	privateKey, err := s.keystore.Decrypt(data, password)
+	if err != nil {
+		return fmt.Errorf("keystore decryption failed: %w", err)
+	}
+	address, err := s.keystore.Address(privateKey)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Address: %s\n", address)
+	return s.writePrivateKey([]byte(hex.EncodeToString(privateKey)+"\n"), password, address+".key", outputPath)
+}
+
+// KeystoreEncrypt exports the private key (hex) into an Ethereum keystore file, the key is read either
+// from a plaintext file or from an encrypted file whose password is reused for the keystore;
+// empty output path means the file is named the way geth names it
+func (s Session) KeystoreEncrypt(inputPath string, outputPath string, plaintextInput bool) error {
+	if err := s.checkKeystoreOutput(inputPath, outputPath); err != nil {
+		return err
+	}
+	privateKey, password, err := s.readPrivateKey(inputPath, plaintextInput)
+	if err != nil {
+		return err
+	}
+	address, err := s.keystore.Address(privateKey)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Address: %s\n", address)
+	if outputPath == "" {
+		outputPath = fmt.Sprintf("UTC--%s--%s", time.Now().UTC().Format("2006-01-02T15-04-05.000000000Z"),
+			strings.ToLower(strings.TrimPrefix(address, "0x")))
+		if err := s.checkKeystoreOutput(inputPath, outputPath); err != nil {
+			return err
+		}
+	}
+
+	output, err := s.keystore.Encrypt(privateKey, password)
+	if err != nil {
+		return fmt.Errorf("keystore encryption failed: %w", err)
+	}
+	err = s.storage.Write(outputPath, output)
+	if err != nil {
+		return fmt.Errorf("failed to save keystore file: %w", err)
+	}
+	fmt.Printf("Successfully exported to %q\n", outputPath)
+	return nil
+}
+
+// ValidatorKeystoreDecrypt decrypts the EIP-2335 keystore file and encrypts the secret key (hex) along with
+// the public fields of the keystore into the container with the same password, so ValidatorKeystoreEncrypt
+// can restore them; the public key and the derivation path are printed for verification
+func (s Session) ValidatorKeystoreDecrypt(inputPath string, outputPath string) error {
+	// make sure the file with output ciphertext doesn't exist
+	if !s.cfg.OverwriteEnabled && s.storage.ResourceExist(outputPath) {
+		return fmt.Errorf("the file with ciphertext already exists at %q: "+
+			"specify different output path with -o flag, remove the file or use --force", outputPath)
+	}
+	key, password, err := s.readValidatorKeystore(inputPath)
+	if err != nil {
+		return err
+	}
+	name := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath)) + ".key"
+	return s.writePrivateKey(key.Text(), password, name, outputPath)
+}
+
+// ValidatorKeystoreVerify checks the password of the EIP-2335 keystore file without writing the secret key
+func (s Session) ValidatorKeystoreVerify(inputPath string) error {
+	if _, _, err := s.readValidatorKeystore(inputPath); err != nil {
+		return err
+	}
+	fmt.Println("The password and the checksum are valid")
+	return nil
+}
+
+// ValidatorKeystoreEncrypt exports the secret key (hex) into an EIP-2335 keystore file, the key is read the same
+// way as by KeystoreEncrypt, the public fields stored along with the key are overridden by the non-empty ones
+// of the template;
+// empty output path means the file is named the way staking-deposit-cli names it
+func (s Session) ValidatorKeystoreEncrypt(inputPath string, outputPath string, plaintextInput bool, template domain.ValidatorKey) error {
+	if err := s.checkKeystoreOutput(inputPath, outputPath); err != nil {
+		return err
+	}
+	plaintext, password, err := s.readKeyText(inputPath, plaintextInput)
+	if err != nil {
+		return err
+	}
+	key, err := domain.ParseValidatorKey(plaintext)
+	if err != nil {
+		return err
+	}
+	if template.Pubkey != "" {
+		key.Pubkey = template.Pubkey
+	}
+	if template.Path != "" {
+		key.Path = template.Path
+	}
+	if template.Description != "" {
+		key.Description = template.Description
+	}
+	output, err := s.validatorKeystore.Encrypt(key, password)
+	if err != nil {
+		return fmt.Errorf("keystore encryption failed: %w", err)
+	}
+	if outputPath == "" {
+		outputPath = fmt.Sprintf("keystore-%d.json", time.Now().Unix())
+		if key.Path != "" {
+			outputPath = fmt.Sprintf("keystore-%s-%d.json", strings.ReplaceAll(key.Path, "/", "_"), time.Now().Unix())
+		}
+		if err := s.checkKeystoreOutput(inputPath, outputPath); err != nil {
+			return err
+		}
+	}
+	err = s.storage.Write(outputPath, output)
+	if err != nil {
+		return fmt.Errorf("failed to save keystore file: %w", err)
+	}
+	fmt.Printf("Successfully exported to %q\n", outputPath)
 	return nil
 }
+
+// readValidatorKeystore decrypts the EIP-2335 keystore file and prints its public fields
+func (s Session) readValidatorKeystore(inputPath string) (*domain.ValidatorKey, []byte, error) {
+	// make sure the keystore file exists
+	if !s.storage.ResourceExist(inputPath) {
+		return nil, nil, fmt.Errorf("the keystore file has not been found at: %q", inputPath)
+	}
+	data, err := s.storage.Read(inputPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read input file: %w", err)
+	}
+	password, err := s.terminal.ReceiveDecryptionPwd()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to receive a password: %w", err)
+	}
+	key, err := s.validatorKeystore.Decrypt(data, password)
+	if err != nil {
+		return nil, nil, fmt.Errorf("keystore decryption failed: %w", err)
+	}
+	if key.Pubkey != "" {
+		fmt.Printf("Public key: 0x%s\n", key.Pubkey)
+	}
+	if key.Path != "" {
+		fmt.Printf("Path: %s\n", key.Path)
+	}
+	return key, password, nil
+}
+
+// checkKeystoreOutput makes sure the input file exists and the keystore file doesn't,
+// empty output path is checked once it's derived from the key
+func (s Session) checkKeystoreOutput(inputPath string, outputPath string) error {
+	if !s.storage.ResourceExist(inputPath) {
+		return fmt.Errorf("the file with the private key has not been found at: %q", inputPath)
+	}
+	if outputPath != "" && !s.cfg.OverwriteEnabled && s.storage.ResourceExist(outputPath) {
+		return fmt.Errorf("the keystore file already exists at %q: "+
+			"specify different output path with -o flag, remove the file or use --force", outputPath)
+	}
+	return nil
+}
+
+// readPrivateKey reads the private key (hex) either from a plaintext file along with a new password
+// or from an encrypted file along with its password
+func (s Session) readPrivateKey(inputPath string, plaintextInput bool) ([]byte, []byte, error) {
+	plaintext, password, err := s.readKeyText(inputPath, plaintextInput)
+	if err != nil {
+		return nil, nil, err
+	}
+	privateKey, err := parsePrivateKey(plaintext)
+	if err != nil {
+		return nil, nil, err
+	}
+	return privateKey, password, nil
+}
+
+// readKeyText reads the plaintext of the key file the same way as readPrivateKey without parsing it
+func (s Session) readKeyText(inputPath string, plaintextInput bool) ([]byte, []byte, error) {
+	var plaintext, password []byte
+	if plaintextInput {
+		data, err := s.storage.Read(inputPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read input file: %w", err)
+		}
+		plaintext = data
+		// receive the password used to derive the keystore key
+		password, err = s.terminal.ReceiveEncryptionPwd()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to receive a password: %w", err)
+		}
+	} else {
+		dto, err := s.readCiphertext(inputPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		// receive the password of the encrypted file, it's reused for the keystore
+		password, err = s.terminal.ReceiveDecryptionPwd()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to receive a password: %w", err)
+		}
+		plaintext, _, err = s.codec.Decrypt(password, dto)
+		if err != nil {
+			return nil, nil, fmt.Errorf("decryption failed: %w", err)
+		}
+	}
+	return plaintext, password, nil
+}
+
+// writePrivateKey encrypts the text of the private key into the container, the name is stored in metadata
+func (s Session) writePrivateKey(plaintext []byte, password []byte, name string, outputPath string) error {
+	var metadata *domain.Metadata
+	if !s.cfg.MetadataDisabled {
+		metadata = &domain.Metadata{Name: name, Mode: 0600, ModTime: time.Now().UTC()}
+	}
+	dto, err := s.codec.Encrypt(password, plaintext, metadata)
+	if err != nil {
+		return fmt.Errorf("encryption failed: %w", err)
+	}
+	output, err := s.container.Marshal(dto)
+	if err != nil {
+		return fmt.Errorf("failed to serialize encrypted data: %w", err)
+	}
+	err = s.storage.Write(outputPath, output)
+	if err != nil {
+		return fmt.Errorf("failed to save encrypted data: %w", err)
+	}
+	fmt.Printf("Successfully converted to %q\n", outputPath)
+	return nil
+}
+
+// parsePrivateKey decodes the private key written as hex with optional "0x" prefix
+func parsePrivateKey(data []byte) ([]byte, error) {
+	text := strings.TrimPrefix(strings.TrimSpace(string(data)), "0x")
+	privateKey, err := hex.DecodeString(text)
+	if err != nil {
+		return nil, fmt.Errorf("the private key has to be written as hex: %w", err)
+	}
+	return privateKey, nil
+}
@@ -8,6 +8,8 @@ import (
 type (
 	// DTO contains all components used for cryptographic algorithm to encrypt/decrypt data
 	DTO struct {
+		// Header is a serialized Header, it's not encrypted but it's authenticated together with the ciphertext
+		Header        []byte
 		KeyDerivation KeyDerivation
 		Nonce         []byte
 		Ciphertext    []byte
@@ -22,6 +24,7 @@ type (
 
 	// DTOBase64 is a proxy type that represents DTO with Base64 encoding
 	DTOBase64 struct {
+		Header        string              `json:"header,omitempty"`
 		KeyDerivation KeyDerivationBase64 `json:"key_derivation"`
 		Nonce         string              `json:"nonce"`
 		Ciphertext    string              `json:"ciphertext"`
@@ -36,8 +39,9 @@ type (
 )
 
 // NewDTO creates a new instance of DTO
-func NewDTO(salt []byte, keyDerivationIterations int, keyDerivationLength int, nonce []byte, ciphertext []byte) *DTO {
+func NewDTO(header []byte, salt []byte, keyDerivationIterations int, keyDerivationLength int, nonce []byte, ciphertext []byte) *DTO {
 	dto := DTO{
+		Header: header,
 		KeyDerivation: KeyDerivation{
 			Salt:       salt,
 			Iterations: keyDerivationIterations,
@@ -52,6 +56,7 @@ func NewDTO(salt []byte, keyDerivationIterations int, keyDerivationLength int, n
 // MarshalJSON implements JSON marshaling into Base64 representation
 func (m DTO) MarshalJSON() ([]byte, error) {
 	tempStruct := DTOBase64{
+		Header: base64.StdEncoding.EncodeToString(m.Header),
 		KeyDerivation: KeyDerivationBase64{
 			Salt:       base64.StdEncoding.EncodeToString(m.KeyDerivation.Salt),
 			Iterations: m.KeyDerivation.Iterations,
@@ -70,6 +75,12 @@ func (m *DTO) UnmarshalJSON(data []byte) error {
 	if err != nil {
 		return err
 	}
+	if tempStruct.Header != "" {
+		m.Header, err = base64.StdEncoding.DecodeString(tempStruct.Header)
+		if err != nil {
+			return err
+		}
+	}
 	m.KeyDerivation.Salt, err = base64.StdEncoding.DecodeString(tempStruct.KeyDerivation.Salt)
 	if err != nil {
 		return err
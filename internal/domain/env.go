@@ -0,0 +1,9 @@
+package domain
+
+type (
+	// EnvVar is an environment variable
+	EnvVar struct {
+		Name  string
+		Value string
+	}
+)
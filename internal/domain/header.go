@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// HEADER_VERSION is the current version of the DTO header format
+const HEADER_VERSION = 1
+
+type (
+	// Header contains parameters describing how the plaintext is packed inside the ciphertext,
+	// it's stored in the clear and authenticated as additional data of the AEAD
+	Header struct {
+		Version int `json:"version"`
+		// Metadata is the original file metadata, if it's stored without encryption
+		Metadata *Metadata `json:"metadata,omitempty"`
+		// MetadataEncrypted tells that the metadata is packed together with the plaintext
+		MetadataEncrypted bool `json:"metadata_encrypted,omitempty"`
+		// Padding is the scheme used to hide the plaintext length
+		Padding string `json:"padding,omitempty"`
+	}
+
+	// Envelope is a record packed together with the plaintext, so it's encrypted as well
+	Envelope struct {
+		// Metadata is inlined to keep envelopes containing bare metadata readable
+		*Metadata
+		// Compression is the algorithm the plaintext has been compressed with
+		Compression string `json:"compression,omitempty"`
+	}
+
+	// Opened is the decrypted plaintext together with the header and envelope of its DTO,
+	// they describe how the plaintext has been packed, so it can be encrypted again the same way
+	Opened struct {
+		Plaintext []byte
+		Metadata  *Metadata
+		// Header is nil for legacy DTO
+		Header   *Header
+		Envelope Envelope
+	}
+
+	// Metadata describes the original file the plaintext has been read from
+	Metadata struct {
+		Name    string            `json:"name"`
+		Mode    uint32            `json:"mode"`
+		ModTime time.Time         `json:"mtime"`
+		UID     *int              `json:"uid,omitempty"`
+		GID     *int              `json:"gid,omitempty"`
+		Xattrs  map[string][]byte `json:"xattrs,omitempty"`
+	}
+)
+
+// NewHeader creates a new instance of Header
+func NewHeader() *Header {
+	return &Header{Version: HEADER_VERSION}
+}
+
+// ParseHeader unmarshals serialized header and checks if its version is supported
+func ParseHeader(data []byte) (*Header, error) {
+	header := &Header{}
+	if err := json.Unmarshal(data, header); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal header: %w", err)
+	}
+	if header.Version < 1 || header.Version > HEADER_VERSION {
+		return nil, fmt.Errorf("unsupported header version: %d", header.Version)
+	}
+	return header, nil
+}
+
+// PackEnvelope packs the envelope record together with the plaintext:
+// 4 bytes of big-endian record length, record JSON, plaintext
+func PackEnvelope(envelope Envelope, plaintext []byte) ([]byte, error) {
+	var envelopeJSON []byte
+	if envelope != (Envelope{}) {
+		var err error
+		envelopeJSON, err = json.Marshal(envelope)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal envelope: %w", err)
+		}
+	}
+	packed := make([]byte, 4, 4+len(envelopeJSON)+len(plaintext))
+	binary.BigEndian.PutUint32(packed, uint32(len(envelopeJSON)))
+	packed = append(packed, envelopeJSON...)
+	return append(packed, plaintext...), nil
+}
+
+// UnpackEnvelope is the reverse of PackEnvelope
+func UnpackEnvelope(packed []byte) (Envelope, []byte, error) {
+	envelope := Envelope{}
+	if len(packed) < 4 {
+		return envelope, nil, fmt.Errorf("envelope is too short")
+	}
+	envelopeLength := binary.BigEndian.Uint32(packed)
+	if uint64(envelopeLength) > uint64(len(packed)-4) {
+		return envelope, nil, fmt.Errorf("invalid envelope length: %d", envelopeLength)
+	}
+	plaintext := packed[4+envelopeLength:]
+	if envelopeLength == 0 {
+		return envelope, plaintext, nil
+	}
+	if err := json.Unmarshal(packed[4:4+envelopeLength], &envelope); err != nil {
+		return envelope, nil, fmt.Errorf("failed to unmarshal envelope: %w", err)
+	}
+	return envelope, plaintext, nil
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+type (
+	// PaperSheet contains everything printed on a paper backup sheet
+	PaperSheet struct {
+		// Label is a free-text label describing the backup
+		Label   string
+		Created time.Time
+		// KeyDerivation contains the parameters of the encrypted data, the salt is printed as part of the text
+		KeyDerivation KeyDerivation
+		NonceLength   int
+		// Lines is the armored text split into numbered lines with per-line checksums
+		Lines []string
+		// QRData is the payload of the QR code
+		QRData []byte
+	}
+)
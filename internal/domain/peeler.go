@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+)
+
+// MAX_FOUNTAIN_BLOCKS limits the number of source blocks of fountain-coded payloads,
+// since the number is read from untrusted input before anything can be verified
+const MAX_FOUNTAIN_BLOCKS = math.MaxUint16
+
+type (
+	// Peeler reconstructs the source blocks of a fountain (Luby transform) code from mixed blocks in any order:
+	// every recovered block is XORed out of the pending mixed blocks containing it, which may recover more blocks in turn
+	Peeler struct {
+		blocks    [][]byte
+		recovered int
+		pending   []*mixedBlock
+	}
+
+	// mixedBlock is XOR of the source blocks with the indices which haven't been recovered yet
+	mixedBlock struct {
+		indices []int
+		data    []byte
+	}
+)
+
+// NewPeeler creates a peeler of the number of source blocks
+func NewPeeler(count int) (*Peeler, error) {
+	if count <= 0 || count > MAX_FOUNTAIN_BLOCKS {
+		return nil, fmt.Errorf("invalid number of blocks: %d", count)
+	}
+	return &Peeler{blocks: make([][]byte, count)}, nil
+}
+
+// Add adds XOR of the source blocks with the indices, the data is modified in place;
+// the indices have to be distinct and within the number of blocks
+func (p *Peeler) Add(indices []int, data []byte) {
+	current := &mixedBlock{data: data}
+	for _, index := range indices {
+		if p.blocks[index] != nil {
+			xorBlock(current.data, p.blocks[index])
+		} else {
+			current.indices = append(current.indices, index)
+		}
+	}
+	queue := []*mixedBlock{current}
+	for len(queue) > 0 {
+		current, queue = queue[0], queue[1:]
+		if len(current.indices) > 1 {
+			p.pending = append(p.pending, current)
+			continue
+		}
+		if len(current.indices) == 0 || p.blocks[current.indices[0]] != nil {
+			continue
+		}
+		index := current.indices[0]
+		p.blocks[index] = current.data
+		p.recovered++
+		remaining := p.pending[:0]
+		for _, other := range p.pending {
+			if other.remove(index) {
+				xorBlock(other.data, p.blocks[index])
+				if len(other.indices) == 1 {
+					queue = append(queue, other)
+					continue
+				}
+			}
+			remaining = append(remaining, other)
+		}
+		p.pending = remaining
+	}
+}
+
+// Recovered returns the number of recovered source blocks
+func (p *Peeler) Recovered() int {
+	return p.recovered
+}
+
+// Complete checks if all the source blocks have been recovered
+func (p *Peeler) Complete() bool {
+	return p.recovered == len(p.blocks)
+}
+
+// Blocks returns the source blocks, the blocks which haven't been recovered are nil
+func (p *Peeler) Blocks() [][]byte {
+	return p.blocks
+}
+
+// remove drops the block index, false is returned if it wasn't there
+func (b *mixedBlock) remove(index int) bool {
+	for i, candidate := range b.indices {
+		if candidate == index {
+			b.indices = append(b.indices[:i], b.indices[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func xorBlock(dst []byte, src []byte) {
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
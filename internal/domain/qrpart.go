@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// QR_PART_PREFIX marks a QR code payload carrying a part of a larger payload
+const QR_PART_PREFIX = "AESGCMQR:"
+
+// qrPartHashLength is the length of the payload hash in bytes, it only has to tell payloads apart
+const qrPartHashLength = 4
+
+type (
+	// QRPart is a chunk of a payload too large for a single QR code,
+	// it's serialized as "AESGCMQR:<index>/<total>:<HASH>:<data>" with 1-based index
+	QRPart struct {
+		Index int
+		Total int
+		// Hash is a truncated SHA-256 of the whole payload
+		Hash []byte
+		Data []byte
+	}
+
+	// MissingQRPartsError is returned when some parts of the payload haven't been provided
+	MissingQRPartsError struct {
+		Total   int
+		Missing []int
+	}
+)
+
+func (e *MissingQRPartsError) Error() string {
+	missing := make([]string, len(e.Missing))
+	for i, index := range e.Missing {
+		missing[i] = strconv.Itoa(index)
+	}
+	return fmt.Sprintf("missing QR code parts %s of %d", strings.Join(missing, ", "), e.Total)
+}
+
+// QRPartHeaderLength returns the maximal length of the part header for the number of parts
+func QRPartHeaderLength(total int) int {
+	digits := len(strconv.Itoa(total))
+	return len(QR_PART_PREFIX) + 2*digits + 1 + 2*qrPartHashLength + 1
+}
+
+// SplitQRParts splits the payload into evenly sized parts of at most chunkSize bytes
+func SplitQRParts(payload []byte, chunkSize int) []QRPart {
+	total := (len(payload) + chunkSize - 1) / chunkSize
+	if total == 0 {
+		total = 1
+	}
+	size := (len(payload) + total - 1) / total
+	hash := qrPartHash(payload)
+	parts := make([]QRPart, 0, total)
+	for i := 0; i < total; i++ {
+		start, end := i*size, (i+1)*size
+		if end > len(payload) {
+			end = len(payload)
+		}
+		parts = append(parts, QRPart{Index: i + 1, Total: total, Hash: hash, Data: payload[start:end]})
+	}
+	return parts
+}
+
+// Bytes serializes the part with its sequence header
+func (p QRPart) Bytes() []byte {
+	// uppercase hash keeps the header within QR alphanumeric mode
+	header := fmt.Sprintf("%s%d/%d:%X:", QR_PART_PREFIX, p.Index, p.Total, p.Hash)
+	return append([]byte(header), p.Data...)
+}
+
+// ParseQRPart deserializes the part, false is returned if the data has no sequence header
+func ParseQRPart(data []byte) (*QRPart, bool, error) {
+	if !bytes.HasPrefix(data, []byte(QR_PART_PREFIX)) {
+		return nil, false, nil
+	}
+	fields := bytes.SplitN(data[len(QR_PART_PREFIX):], []byte(":"), 3)
+	if len(fields) != 3 {
+		return nil, true, fmt.Errorf("malformed QR code part header")
+	}
+	var part QRPart
+	if _, err := fmt.Sscanf(string(fields[0]), "%d/%d", &part.Index, &part.Total); err != nil {
+		return nil, true, fmt.Errorf("malformed QR code part sequence: %w", err)
+	}
+	// the total is untrusted and bounds the loops over the parts, so it's limited like the number of fountain blocks
+	if part.Total < 1 || part.Total > MAX_FOUNTAIN_BLOCKS || part.Index < 1 || part.Index > part.Total {
+		return nil, true, fmt.Errorf("invalid QR code part sequence: %s", fields[0])
+	}
+	hash, err := hex.DecodeString(string(fields[1]))
+	if err != nil || len(hash) != qrPartHashLength {
+		return nil, true, fmt.Errorf("malformed QR code part hash: %s", fields[1])
+	}
+	part.Hash = hash
+	part.Data = fields[2]
+	return &part, true, nil
+}
+
+// JoinQRParts reassembles the payload from the parts given in any order, duplicates are ignored
+func JoinQRParts(parts []QRPart) ([]byte, error) {
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("no QR code parts provided")
+	}
+	first := parts[0]
+	chunks := make(map[int][]byte)
+	for _, part := range parts {
+		if part.Total != first.Total || !bytes.Equal(part.Hash, first.Hash) {
+			return nil, fmt.Errorf("QR code parts belong to different payloads")
+		}
+		chunks[part.Index] = part.Data
+	}
+	var missing []int
+	for i := 1; i <= first.Total; i++ {
+		if _, ok := chunks[i]; !ok {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &MissingQRPartsError{Total: first.Total, Missing: missing}
+	}
+	var payload []byte
+	for i := 1; i <= first.Total; i++ {
+		payload = append(payload, chunks[i]...)
+	}
+	if !bytes.Equal(qrPartHash(payload), first.Hash) {
+		return nil, fmt.Errorf("reassembled QR code payload doesn't match its hash")
+	}
+	return payload, nil
+}
+
+func qrPartHash(payload []byte) []byte {
+	sum := sha256.Sum256(payload)
+	return sum[:qrPartHashLength]
+}
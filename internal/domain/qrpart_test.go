@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestQRParts(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	payload := make([]byte, 1000)
+	rnd.Read(payload)
+	parts := SplitQRParts(payload, 300)
+	if len(parts) != 4 {
+		t.Fatalf("unexpected number of parts: %d", len(parts))
+	}
+
+	// the parts survive serialization and are joined in any order, duplicates are ignored
+	var received []QRPart
+	for _, part := range parts {
+		if len(part.Data) > 300 {
+			t.Fatalf("part %d exceeds the chunk size: %d", part.Index, len(part.Data))
+		}
+		parsed, ok, err := ParseQRPart(part.Bytes())
+		if !ok || err != nil {
+			t.Fatalf("failed to parse part %d: %v", part.Index, err)
+		}
+		received = append(received, *parsed)
+	}
+	received = append(received, received[0])
+	rnd.Shuffle(len(received), func(i, j int) { received[i], received[j] = received[j], received[i] })
+	joined, err := JoinQRParts(received)
+	if err != nil {
+		t.Fatalf("failed to join parts: %s", err)
+	}
+	if !bytes.Equal(joined, payload) {
+		t.Fatal("joined payload doesn't match")
+	}
+
+	// tampered data is caught by the payload hash
+	tampered := append([]QRPart(nil), parts...)
+	tampered[2].Data = append([]byte{tampered[2].Data[0] ^ 1}, tampered[2].Data[1:]...)
+	if _, err := JoinQRParts(tampered); err == nil {
+		t.Fatal("expected tampered part to be detected")
+	}
+	other := SplitQRParts(payload[:900], 300)
+	if _, err := JoinQRParts([]QRPart{parts[0], other[1]}); err == nil {
+		t.Fatal("expected parts of different payloads to be rejected")
+	}
+}
+
+func TestQRPartsMissing(t *testing.T) {
+	parts := SplitQRParts(bytes.Repeat([]byte("payload "), 100), 200)
+	_, err := JoinQRParts([]QRPart{parts[2], parts[0]})
+	var missing *MissingQRPartsError
+	if !errors.As(err, &missing) || missing.Total != 4 || len(missing.Missing) != 2 {
+		t.Fatalf("expected MissingQRPartsError, got: %v", err)
+	}
+	if err.Error() != "missing QR code parts 2, 4 of 4" {
+		t.Fatalf("unexpected error message: %s", err)
+	}
+}
+
+func TestParseQRPart(t *testing.T) {
+	if _, ok, err := ParseQRPart([]byte("plain payload")); ok || err != nil {
+		t.Fatalf("payload without header has been recognized as a part: %v", err)
+	}
+	for _, data := range []string{
+		"AESGCMQR:1/2:0A0B0C0D",
+		"AESGCMQR:3/2:0A0B0C0D:data",
+		"AESGCMQR:0/2:0A0B0C0D:data",
+		"AESGCMQR:1/2:0A0B:data",
+		"AESGCMQR:x/2:0A0B0C0D:data",
+		"AESGCMQR:1/9223372036854775807:0A0B0C0D:x",
+		"AESGCMQR:1/65536:0A0B0C0D:x",
+	} {
+		if _, ok, err := ParseQRPart([]byte(data)); !ok || err == nil {
+			t.Fatalf("expected %q to be rejected", data)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package domain
+
+type (
+	// Repair reports bytes of a text line corrected with error correction code,
+	// so the damaged line of a backup copy can be fixed
+	Repair struct {
+		// Line is 1-based number of the body line, the same as printed on numbered lines
+		Line int
+		// Bytes is the number of corrected bytes
+		Bytes int
+	}
+)
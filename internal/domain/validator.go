@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type (
+	// ValidatorKey is a BLS12-381 validator signing key along with the public fields of its EIP-2335 keystore
+	ValidatorKey struct {
+		Secret []byte
+		// Pubkey is the hex encoded BLS public key, it's derived from the secret when the keystore is written
+		Pubkey string
+		// Path is the EIP-2334 derivation path of the key, it may be empty
+		Path        string
+		Description string
+		UUID        string
+	}
+)
+
+// Text serializes the key as the hex secret on the first line followed by "<Field>: <value>" lines
+// of the non-empty public fields, values which don't fit a line as is are quoted
+func (k ValidatorKey) Text() []byte {
+	var text strings.Builder
+	text.WriteString(hex.EncodeToString(k.Secret) + "\n")
+	for _, field := range k.fields() {
+		if *field.value != "" {
+			fmt.Fprintf(&text, "%s: %s\n", field.name, quoteValidatorField(*field.value))
+		}
+	}
+	return []byte(text.String())
+}
+
+// ParseValidatorKey deserializes the key written by Text, the secret may be prefixed with "0x",
+// so a file with the bare hex secret is a valid key without the public fields
+func ParseValidatorKey(data []byte) (*ValidatorKey, error) {
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	secret, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(lines[0]), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("the secret key has to be written as hex: %w", err)
+	}
+	key := &ValidatorKey{Secret: secret}
+	fields := key.fields()
+	for i, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			return nil, fmt.Errorf("malformed validator key field on line %d", i+2)
+		}
+		value = strings.TrimSpace(value)
+		if strings.HasPrefix(value, `"`) {
+			if value, err = strconv.Unquote(value); err != nil {
+				return nil, fmt.Errorf("malformed value of validator key field %q: %w", name, err)
+			}
+		}
+		known := false
+		for _, field := range fields {
+			if strings.EqualFold(strings.TrimSpace(name), field.name) {
+				*field.value, known = value, true
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown validator key field: %q", name)
+		}
+	}
+	return key, nil
+}
+
+func (k *ValidatorKey) fields() []struct {
+	name  string
+	value *string
+} {
+	return []struct {
+		name  string
+		value *string
+	}{
+		{"Pubkey", &k.Pubkey},
+		{"Path", &k.Path},
+		{"Description", &k.Description},
+		{"UUID", &k.UUID},
+	}
+}
+
+// quoteValidatorField quotes the value if it has characters or surrounding spaces that don't survive a line
+func quoteValidatorField(value string) string {
+	quoted := strconv.Quote(value)
+	if quoted != `"`+value+`"` || value != strings.TrimSpace(value) || strings.HasPrefix(value, `"`) {
+		return quoted
+	}
+	return value
+}
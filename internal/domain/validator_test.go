@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidatorKeyText(t *testing.T) {
+	secret := bytes.Repeat([]byte{0xab}, 32)
+	key := ValidatorKey{
+		Secret:      secret,
+		Pubkey:      "9612d7a727c9d0a22e185a1c768478dfe919cada9266988cb32359c11f2b7b27f4ae4040902382ae2910c15e2b420d07",
+		Path:        "m/12381/3600/0/0/0",
+		Description: " \"validator\"\nkey ",
+		UUID:        "64625def-3331-4eea-ab6f-782f3ed16a83",
+	}
+	text := key.Text()
+	if !strings.HasPrefix(string(text), strings.Repeat("ab", 32)+"\nPubkey: 9612") || !strings.Contains(string(text), "\nPath: m/12381/3600/0/0/0\n") {
+		t.Fatalf("unexpected text: %q", text)
+	}
+	parsed, err := ParseValidatorKey(text)
+	if err != nil {
+		t.Fatalf("failed to parse key: %s", err)
+	}
+	if !reflect.DeepEqual(*parsed, key) {
+		t.Fatalf("unexpected parsed key: %+v", parsed)
+	}
+
+	// the bare hex secret is a key without the public fields
+	parsed, err = ParseValidatorKey([]byte("0x" + strings.Repeat("AB", 32) + "\r\n"))
+	if err != nil || !bytes.Equal(parsed.Secret, secret) || parsed.Pubkey != "" || parsed.Path != "" {
+		t.Fatalf("unexpected bare key: %+v, %v", parsed, err)
+	}
+
+	for _, invalid := range []string{
+		"xyz\n",
+		strings.Repeat("ab", 32) + "\nPath m/12381\n",
+		strings.Repeat("ab", 32) + "\nName: key\n",
+		strings.Repeat("ab", 32) + "\nDescription: \"unterminated\n",
+	} {
+		if _, err := ParseValidatorKey([]byte(invalid)); err == nil {
+			t.Fatalf("expected error for %q", invalid)
+		}
+	}
+}
@@ -18,7 +18,8 @@ func NewAESGCM() *AESGCM {
 	return &AESGCM{}
 }
 
-// Encrypt performs the encryption and returns ciphertext
+// Encrypt performs the encryption and returns ciphertext,
+// additional data is authenticated but not encrypted
 func (aes AESGCM) Encrypt(
 	password []byte,
 	salt []byte,
@@ -26,6 +27,7 @@ func (aes AESGCM) Encrypt(
 	derivationLength int,
 	nonce []byte,
 	plaintext []byte,
+	additionalData []byte,
 ) ([]byte, error) {
 	key := deriveKey(password, salt, derivationIter, derivationLength)
 	aesgcm, err := initCipher(key)
@@ -39,7 +41,7 @@ func (aes AESGCM) Encrypt(
 			aesgcm.NonceSize(),
 		)
 	}
-	return aesgcm.Seal(nil, nonce, plaintext, nil), nil
+	return aesgcm.Seal(nil, nonce, plaintext, additionalData), nil
 }
 
 // Decrypt performs the decryption and returns the plaintext
@@ -49,6 +51,7 @@ func (aes AESGCM) Decrypt(password []byte,
 	derivationLength int,
 	nonce []byte,
 	ciphertext []byte,
+	additionalData []byte,
 ) ([]byte, error) {
 	key := deriveKey(password, salt, derivationIter, derivationLength)
 	aesgcm, err := initCipher(key)
@@ -62,7 +65,7 @@ func (aes AESGCM) Decrypt(password []byte,
 			aesgcm.NonceSize(),
 		)
 	}
-	return aesgcm.Open(nil, nonce, ciphertext, nil)
+	return aesgcm.Open(nil, nonce, ciphertext, additionalData)
 }
 
 func deriveKey(password []byte, salt []byte, derivationIter int, derivationLength int) []byte {
@@ -0,0 +1,92 @@
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ALGORITHM_NONE disables compression
+const ALGORITHM_NONE = "none"
+
+// ALGORITHM_GZIP selects gzip compression
+const ALGORITHM_GZIP = "gzip"
+
+// ALGORITHM_ZSTD selects Zstandard compression
+const ALGORITHM_ZSTD = "zstd"
+
+type (
+	// Compression is a component responsible for compression/decompression of data
+	Compression struct{}
+)
+
+func NewCompression() *Compression {
+	return &Compression{}
+}
+
+// Compress compresses data with the specified algorithm
+func (c Compression) Compress(algorithm string, data []byte) ([]byte, error) {
+	switch algorithm {
+	case ALGORITHM_NONE, "":
+		return data, nil
+	case ALGORITHM_GZIP:
+		var buffer bytes.Buffer
+		writer, err := gzip.NewWriterLevel(&buffer, gzip.BestCompression)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := writer.Write(data); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		return buffer.Bytes(), nil
+	case ALGORITHM_ZSTD:
+		encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBestCompression))
+		if err != nil {
+			return nil, err
+		}
+		defer encoder.Close()
+		return encoder.EncodeAll(data, nil), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression algorithm: %q", algorithm)
+	}
+}
+
+// Decompress decompresses data with the specified algorithm,
+// the output is limited to maxSize bytes to protect against decompression bombs
+func (c Compression) Decompress(algorithm string, data []byte, maxSize int64) ([]byte, error) {
+	var reader io.Reader
+	switch algorithm {
+	case ALGORITHM_NONE, "":
+		return data, nil
+	case ALGORITHM_GZIP:
+		gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	case ALGORITHM_ZSTD:
+		decoder, err := zstd.NewReader(bytes.NewReader(data), zstd.WithDecoderMaxMemory(uint64(maxSize)))
+		if err != nil {
+			return nil, err
+		}
+		defer decoder.Close()
+		reader = decoder
+	default:
+		return nil, fmt.Errorf("unsupported compression algorithm: %q", algorithm)
+	}
+	output, err := io.ReadAll(io.LimitReader(reader, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(output)) > maxSize {
+		return nil, fmt.Errorf("decompressed data exceeds the limit of %d bytes", maxSize)
+	}
+	return output, nil
+}
@@ -0,0 +1,27 @@
+package compression
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDecompressLimit(t *testing.T) {
+	compression := NewCompression()
+	bomb := make([]byte, 1<<20)
+	for _, algorithm := range []string{ALGORITHM_GZIP, ALGORITHM_ZSTD} {
+		compressed, err := compression.Compress(algorithm, bomb)
+		if err != nil {
+			t.Fatalf("failed to compress with %s: %s", algorithm, err)
+		}
+		if _, err := compression.Decompress(algorithm, compressed, 1<<10); err == nil {
+			t.Fatalf("decompression limit hasn't been enforced with %s", algorithm)
+		}
+		decompressed, err := compression.Decompress(algorithm, compressed, 1<<20)
+		if err != nil {
+			t.Fatalf("failed to decompress with %s: %s", algorithm, err)
+		}
+		if !bytes.Equal(decompressed, bomb) {
+			t.Fatalf("decompressed data doesn't match with %s", algorithm)
+		}
+	}
+}
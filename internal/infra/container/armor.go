@@ -0,0 +1,222 @@
+package container
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ARMOR_VERSION is the version of the armor layout written into "Format-Version" header
+const ARMOR_VERSION = "1"
+
+const armorLineLength = 64
+
+var (
+	armorBegin = []byte("-----BEGIN AESGCM ENCRYPTED MESSAGE-----")
+	armorEnd   = []byte("-----END AESGCM ENCRYPTED MESSAGE-----")
+)
+
+// armor wraps data into PEM-like ASCII armor with headers, 64-column Base64 body and CRC24 checksum
+func armor(data []byte, headers map[string]string) []byte {
+	var buffer bytes.Buffer
+	buffer.Write(armorBegin)
+	buffer.WriteByte('\n')
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&buffer, "%s: %s\n", key, headers[key])
+	}
+	buffer.WriteByte('\n')
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > armorLineLength {
+		buffer.WriteString(encoded[:armorLineLength])
+		buffer.WriteByte('\n')
+		encoded = encoded[armorLineLength:]
+	}
+	buffer.WriteString(encoded)
+	buffer.WriteByte('\n')
+	checksum := crc24(data)
+	buffer.WriteByte('=')
+	buffer.WriteString(base64.StdEncoding.EncodeToString([]byte{byte(checksum >> 16), byte(checksum >> 8), byte(checksum)}))
+	buffer.WriteByte('\n')
+	buffer.Write(armorEnd)
+	buffer.WriteByte('\n')
+	return buffer.Bytes()
+}
+
+type (
+	// armorBlock is an armored block split into headers and body lines
+	armorBlock struct {
+		headers  map[string]string
+		lines    []armorLine
+		checksum string
+	}
+
+	// armorLine is a body line of the armor, numbered lines transcribed from a paper sheet
+	// carry their number, a line which fails its checksum keeps the error
+	armorLine struct {
+		number  int
+		content string
+		err     error
+	}
+)
+
+// dearmor finds the first armored block in the text (which may be surrounded by arbitrary text,
+// e.g. a pasted email body with quoting) and returns its decoded body
+func dearmor(text []byte) ([]byte, map[string]string, error) {
+	block, err := scanArmor(text)
+	if err != nil {
+		return nil, nil, err
+	}
+	var body strings.Builder
+	for _, line := range block.lines {
+		if line.err != nil {
+			return nil, nil, line.err
+		}
+		body.WriteString(line.content)
+	}
+	data, err := base64.StdEncoding.DecodeString(body.String())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode armored body: %w", err)
+	}
+	if err := block.verify(data); err != nil {
+		return nil, nil, err
+	}
+	return data, block.headers, nil
+}
+
+// scanArmor finds the first armored block in the text and splits it into headers and body lines
+func scanArmor(text []byte) (*armorBlock, error) {
+	start := bytes.Index(text, armorBegin)
+	if start < 0 {
+		return nil, fmt.Errorf("armor header line not found")
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(text[start+len(armorBegin):]))
+	scanner.Buffer(nil, len(text)+1)
+	block := &armorBlock{headers: make(map[string]string)}
+	inHeaders, ended := true, false
+	scanner.Scan() // the rest of the header line
+	for scanner.Scan() {
+		line := unquoteLine(scanner.Text())
+		if line == string(armorEnd) {
+			ended = true
+			break
+		}
+		if inHeaders {
+			if line == "" {
+				inHeaders = false
+				continue
+			}
+			if key, value, ok := strings.Cut(line, ": "); ok && !strings.ContainsAny(key, " =") {
+				block.headers[key] = value
+				continue
+			}
+			// headers are optional, so the body may start right away
+			inHeaders = false
+		}
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "="):
+			block.checksum = line[1:]
+		case strings.Contains(line, " "): // numbered line transcribed from a paper sheet
+			number, content, err := parseNumberedLine(line)
+			block.lines = append(block.lines, armorLine{number, content, err})
+		default:
+			block.lines = append(block.lines, armorLine{content: line})
+		}
+	}
+	if !ended {
+		return nil, fmt.Errorf("armor tail line not found")
+	}
+	return block, nil
+}
+
+// verify compares the decoded body with CRC24 checksum line, if it's present
+func (b armorBlock) verify(data []byte) error {
+	if b.checksum == "" {
+		return nil
+	}
+	expected, err := base64.StdEncoding.DecodeString(b.checksum)
+	if err != nil || len(expected) != 3 {
+		return fmt.Errorf("invalid armor checksum line: %q", b.checksum)
+	}
+	actual := crc24(data)
+	if uint32(expected[0])<<16|uint32(expected[1])<<8|uint32(expected[2]) != actual {
+		return fmt.Errorf("armor checksum mismatch: the text has been damaged")
+	}
+	return nil
+}
+
+// numberLines prefixes body lines of the armor with line numbers and appends per-line checksums,
+// so every line of a transcribed paper copy can be verified on its own
+func numberLines(armored []byte) []string {
+	lines := strings.Split(strings.TrimRight(string(armored), "\n"), "\n")
+	bodyStart := 0
+	for i, line := range lines {
+		if line == "" {
+			bodyStart = i + 1
+			break
+		}
+	}
+	width := len(fmt.Sprint(len(lines)))
+	for i := bodyStart; i < len(lines)-1; i++ {
+		if strings.HasPrefix(lines[i], "=") {
+			continue
+		}
+		lines[i] = fmt.Sprintf("%0*d %s %s", width, i-bodyStart+1, lines[i], lineChecksum(i-bodyStart+1, lines[i]))
+	}
+	return lines
+}
+
+// parseNumberedLine verifies the checksum of "NN BODY CHECKSUM" line and returns its number and body,
+// the number is zero if it can't be read
+func parseNumberedLine(line string) (int, string, error) {
+	fields := strings.Fields(line)
+	var number int
+	if _, err := fmt.Sscanf(fields[0], "%d", &number); err != nil || number <= 0 {
+		return 0, "", fmt.Errorf("malformed armor line number: %q", line)
+	}
+	if len(fields) != 3 {
+		return number, "", fmt.Errorf("malformed armor line: %q", line)
+	}
+	if !strings.EqualFold(lineChecksum(number, fields[1]), fields[2]) {
+		return number, "", fmt.Errorf("transcription error in armor line %d: checksum mismatch", number)
+	}
+	return number, fields[1], nil
+}
+
+// lineChecksum is 4 hex digits of CRC24 of the line number and its content
+func lineChecksum(number int, line string) string {
+	return fmt.Sprintf("%04X", crc24([]byte(fmt.Sprintf("%d:%s", number, line)))>>8)
+}
+
+// unquoteLine strips email quoting and surrounding whitespace
+func unquoteLine(line string) string {
+	line = strings.TrimSpace(line)
+	for strings.HasPrefix(line, ">") {
+		line = strings.TrimSpace(line[1:])
+	}
+	return line
+}
+
+// crc24 computes the OpenPGP CRC-24 checksum (RFC 4880, section 6.1)
+func crc24(data []byte) uint32 {
+	crc := uint32(0xB704CE)
+	for _, b := range data {
+		crc ^= uint32(b) << 16
+		for i := 0; i < 8; i++ {
+			crc <<= 1
+			if crc&0x1000000 != 0 {
+				crc ^= 0x1864CFB
+			}
+		}
+	}
+	return crc & 0xFFFFFF
+}
@@ -0,0 +1,65 @@
+package container
+
+import (
+	"fmt"
+	"strings"
+)
+
+// base45Alphabet is RFC 9285 alphabet, it's the character set of QR code alphanumeric mode
+const base45Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// encodeBase45 encodes every two bytes as three characters (RFC 9285)
+func encodeBase45(data []byte) string {
+	var output strings.Builder
+	for i := 0; i < len(data); i += 2 {
+		if i+1 == len(data) {
+			n := int(data[i])
+			output.WriteByte(base45Alphabet[n%45])
+			output.WriteByte(base45Alphabet[n/45])
+			break
+		}
+		n := int(data[i])<<8 | int(data[i+1])
+		output.WriteByte(base45Alphabet[n%45])
+		output.WriteByte(base45Alphabet[n/45%45])
+		output.WriteByte(base45Alphabet[n/(45*45)])
+	}
+	return output.String()
+}
+
+// decodeBase45 decodes RFC 9285 text, start is the offset of the text in the input for error reporting
+func decodeBase45(input []byte, text string, start int) ([]byte, error) {
+	if len(text)%3 == 1 {
+		return nil, newTranscriptionError(input, start+len(text)-1, "", "the text is truncated")
+	}
+	output := make([]byte, 0, len(text)*2/3)
+	for i := 0; i < len(text); i += 3 {
+		chunk := text[i:min(i+3, len(text))]
+		n := 0
+		for j := len(chunk) - 1; j >= 0; j-- {
+			value := strings.IndexByte(base45Alphabet, chunk[j])
+			if value < 0 {
+				return nil, newTranscriptionError(input, start+i+j, "", fmt.Sprintf("invalid character %q", chunk[j]))
+			}
+			n = n*45 + value
+		}
+		if len(chunk) == 3 {
+			if n > 0xffff {
+				return nil, newTranscriptionError(input, start+i, "", "invalid Base45 triplet")
+			}
+			output = append(output, byte(n>>8), byte(n))
+		} else {
+			if n > 0xff {
+				return nil, newTranscriptionError(input, start+i, "", "invalid Base45 pair")
+			}
+			output = append(output, byte(n))
+		}
+	}
+	return output, nil
+}
+
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
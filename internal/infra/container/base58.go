@@ -0,0 +1,140 @@
+package container
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+)
+
+// base58CheckPrefix marks Base58Check text, the prefix isn't a part of the Base58 alphabet
+// because of ":" so it can't be confused with the payload
+const base58CheckPrefix = "AESGCM58:"
+
+// base58CheckVersion is the version byte of the Base58Check payload
+const base58CheckVersion = 0x01
+
+// base58MaxLocateLength limits the length of text where a transcription error is searched for,
+// since every attempt requires decoding of the whole text
+const base58MaxLocateLength = 512
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// encodeBase58Check encodes version byte, data and the first 4 bytes of double SHA-256
+func encodeBase58Check(data []byte) string {
+	payload := append([]byte{base58CheckVersion}, data...)
+	return encodeBase58(append(payload, base58Checksum(payload)...))
+}
+
+func decodeBase58CheckText(text []byte, start int) ([]byte, error) {
+	chars, positions := stripText(text, start)
+	if len(chars) == 0 {
+		return nil, fmt.Errorf("encoded text is empty")
+	}
+	for i := 0; i < len(chars); i++ {
+		if strings.IndexByte(base58Alphabet, chars[i]) < 0 {
+			return nil, newTranscriptionError(text, positions[i], "", fmt.Sprintf("invalid character %q", chars[i]))
+		}
+	}
+	data, ok := decodeBase58Check(chars)
+	if ok {
+		return data, nil
+	}
+	// try to locate a single mistyped character
+	if len(chars) <= base58MaxLocateLength {
+		candidate, suggestion, ambiguous := -1, "", false
+		mutated := []byte(chars)
+		for i := range mutated {
+			original := mutated[i]
+			for j := 0; j < len(base58Alphabet); j++ {
+				if base58Alphabet[j] == original {
+					continue
+				}
+				mutated[i] = base58Alphabet[j]
+				if _, ok := decodeBase58Check(string(mutated)); ok {
+					ambiguous = ambiguous || candidate >= 0
+					candidate, suggestion = i, string(base58Alphabet[j])
+				}
+			}
+			mutated[i] = original
+		}
+		if candidate >= 0 && !ambiguous {
+			return nil, newTranscriptionError(text, positions[candidate], suggestion, "")
+		}
+	}
+	return nil, fmt.Errorf("transcription error: Base58Check checksum mismatch")
+}
+
+func decodeBase58Check(chars string) ([]byte, bool) {
+	decoded := decodeBase58(chars)
+	if len(decoded) < 5 || decoded[0] != base58CheckVersion {
+		return nil, false
+	}
+	payload, checksum := decoded[:len(decoded)-4], decoded[len(decoded)-4:]
+	if !bytes.Equal(base58Checksum(payload), checksum) {
+		return nil, false
+	}
+	return payload[1:], true
+}
+
+func base58Checksum(payload []byte) []byte {
+	first := sha256.Sum256(payload)
+	second := sha256.Sum256(first[:])
+	return second[:4]
+}
+
+func encodeBase58(data []byte) string {
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+	// big-endian base-58 digits of the number
+	digits := make([]byte, 0, len(data)*138/100+1)
+	for _, b := range data[zeros:] {
+		carry := int(b)
+		for i := range digits {
+			carry += int(digits[i]) << 8
+			digits[i] = byte(carry % 58)
+			carry /= 58
+		}
+		for carry > 0 {
+			digits = append(digits, byte(carry%58))
+			carry /= 58
+		}
+	}
+	encoded := make([]byte, zeros+len(digits))
+	for i := 0; i < zeros; i++ {
+		encoded[i] = base58Alphabet[0]
+	}
+	for i, digit := range digits {
+		encoded[len(encoded)-1-i] = base58Alphabet[digit]
+	}
+	return string(encoded)
+}
+
+// decodeBase58 expects only valid characters
+func decodeBase58(chars string) []byte {
+	zeros := 0
+	for zeros < len(chars) && chars[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+	// little-endian bytes of the number
+	var number []byte
+	for i := zeros; i < len(chars); i++ {
+		carry := strings.IndexByte(base58Alphabet, chars[i])
+		for j := range number {
+			carry += int(number[j]) * 58
+			number[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			number = append(number, byte(carry))
+			carry >>= 8
+		}
+	}
+	decoded := make([]byte, zeros+len(number))
+	for i, b := range number {
+		decoded[len(decoded)-1-i] = b
+	}
+	return decoded
+}
@@ -0,0 +1,237 @@
+package container
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// bech32HRP is the human-readable part of the Bech32 text, followed by the segment index and count
+const bech32HRP = "aesgcm"
+
+// bech32mConstant is the checksum constant of Bech32m (BIP 350)
+const bech32mConstant = 0x2bc830a3
+
+// bech32SegmentLength is the amount of data bytes of a single segment, 64 characters,
+// so every segment with its header and checksum stays within the 90 characters limit of BIP 173
+const bech32SegmentLength = 40
+
+// bech32MaxLength is the length up to which Bech32 checksum guarantees to detect 4 errors and locate a single one
+const bech32MaxLength = 90
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+var bech32Generator = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+// encodeBech32Segments splits data into Bech32m strings of at most 90 characters, one per line,
+// the human-readable part of each one is "aesgcm<index>of<count>" to detect reordered and missing lines
+func encodeBech32Segments(data []byte) (string, error) {
+	count := (len(data) + bech32SegmentLength - 1) / bech32SegmentLength
+	var output strings.Builder
+	for i := 0; i < count; i++ {
+		end := (i + 1) * bech32SegmentLength
+		if end > len(data) {
+			end = len(data)
+		}
+		segment, err := encodeBech32(bech32SegmentHRP(i, count), data[i*bech32SegmentLength:end])
+		if err != nil {
+			return "", err
+		}
+		if len(segment) > bech32MaxLength {
+			return "", fmt.Errorf("data of %d bytes is too large for bech32 encoding", len(data))
+		}
+		output.WriteString(segment)
+		output.WriteByte('\n')
+	}
+	return output.String(), nil
+}
+
+// encodeBech32 encodes data as a single Bech32m string
+func encodeBech32(hrp string, data []byte) (string, error) {
+	values, err := convertBits(data, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	checksum := bech32Checksum(hrp, values)
+	var output strings.Builder
+	output.WriteString(hrp)
+	output.WriteByte('1')
+	for _, value := range append(values, checksum...) {
+		output.WriteByte(bech32Charset[value])
+	}
+	return output.String(), nil
+}
+
+func bech32SegmentHRP(index int, count int) string {
+	return fmt.Sprintf("%s%dof%d", bech32HRP, index+1, count)
+}
+
+// decodeBech32Text decodes the segments, one per line, whitespace within a line is ignored
+func decodeBech32Text(text []byte, start int) ([]byte, error) {
+	var output []byte
+	count, index := 0, 0
+	for _, line := range bytes.SplitAfter(text[start:], []byte{'\n'}) {
+		chars, positions := stripText(text[:start+len(line)], start)
+		start += len(line)
+		if len(chars) == 0 {
+			continue
+		}
+		if count == 0 {
+			var err error
+			if count, err = bech32SegmentCount(chars); err != nil {
+				return nil, newTranscriptionError(text, positions[0], "", err.Error())
+			}
+		}
+		if index >= count {
+			return nil, newTranscriptionError(text, positions[0], "", fmt.Sprintf("unexpected bech32 segment beyond %d segments", count))
+		}
+		data, err := decodeBech32Segment(text, chars, positions, bech32SegmentHRP(index, count))
+		if err != nil {
+			return nil, err
+		}
+		output = append(output, data...)
+		index++
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("bech32 text is empty")
+	}
+	if index != count {
+		return nil, fmt.Errorf("bech32 text is truncated: %d of %d segments", index, count)
+	}
+	return output, nil
+}
+
+// bech32SegmentCount parses the segment count from the human-readable part of the first segment
+func bech32SegmentCount(chars string) (int, error) {
+	chars = strings.ToLower(chars)
+	prefix := bech32HRP + "1of"
+	separator := strings.LastIndexByte(chars, '1')
+	if !strings.HasPrefix(chars, prefix) || separator < len(prefix) {
+		return 0, fmt.Errorf("the first bech32 segment has to start with %q", prefix)
+	}
+	count, err := strconv.Atoi(chars[len(prefix):separator])
+	if err != nil || count <= 0 {
+		return 0, fmt.Errorf("invalid bech32 segment count: %q", chars[len(prefix):separator])
+	}
+	return count, nil
+}
+
+func decodeBech32Segment(text []byte, chars string, positions []int, expectedHRP string) ([]byte, error) {
+	if strings.ToLower(chars) != chars && strings.ToUpper(chars) != chars {
+		return nil, newTranscriptionError(text, positions[0], "", "bech32 text must not mix upper and lower case")
+	}
+	if len(chars) > bech32MaxLength {
+		return nil, newTranscriptionError(text, positions[0], "", fmt.Sprintf("bech32 segment exceeds %d characters", bech32MaxLength))
+	}
+	chars = strings.ToLower(chars)
+	separator := strings.LastIndexByte(chars, '1')
+	if separator < 1 || len(chars)-separator-1 < 6 {
+		return nil, newTranscriptionError(text, positions[len(positions)-1], "", "bech32 segment is truncated")
+	}
+	if hrp := chars[:separator]; hrp != expectedHRP {
+		return nil, newTranscriptionError(text, positions[0], "", fmt.Sprintf("expected bech32 segment %q, got %q", expectedHRP, hrp))
+	}
+	values := make([]byte, 0, len(chars)-separator-1)
+	for i := separator + 1; i < len(chars); i++ {
+		value := strings.IndexByte(bech32Charset, chars[i])
+		if value < 0 {
+			return nil, newTranscriptionError(text, positions[i], "", fmt.Sprintf("invalid character %q", chars[i]))
+		}
+		values = append(values, byte(value))
+	}
+	syndrome := bech32Polymod(append(bech32HRPExpand(expectedHRP), values...)) ^ bech32mConstant
+	if syndrome != 0 {
+		if position, value, ok := bech32Locate(syndrome, values); ok {
+			return nil, newTranscriptionError(text, positions[separator+1+position], string(bech32Charset[value]), "")
+		}
+		return nil, newTranscriptionError(text, positions[0], "", "bech32 checksum mismatch in the segment starting here")
+	}
+	return convertBits(values[:len(values)-6], 5, 8, false)
+}
+
+// bech32Locate finds a single substitution which explains the syndrome: the checksum is linear,
+// so an error e at position p changes the polymod by e shifted through the remaining positions
+func bech32Locate(syndrome uint32, values []byte) (int, byte, bool) {
+	var residues [32]uint32
+	for e := range residues {
+		residues[e] = uint32(e)
+	}
+	position, value, found := -1, byte(0), false
+	for p := len(values) - 1; p >= 0; p-- {
+		for e := 1; e < 32; e++ {
+			if residues[e] == syndrome {
+				if found {
+					return 0, 0, false // ambiguous
+				}
+				position, value, found = p, values[p]^byte(e), true
+			}
+			residues[e] = bech32Shift(residues[e])
+		}
+	}
+	return position, value, found
+}
+
+func bech32Shift(chk uint32) uint32 {
+	top := chk >> 25
+	chk = (chk & 0x1ffffff) << 5
+	for i := 0; i < 5; i++ {
+		if (top>>i)&1 == 1 {
+			chk ^= bech32Generator[i]
+		}
+	}
+	return chk
+}
+
+func bech32Polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, value := range values {
+		chk = bech32Shift(chk) ^ uint32(value)
+	}
+	return chk
+}
+
+func bech32HRPExpand(hrp string) []byte {
+	expanded := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]>>5)
+	}
+	expanded = append(expanded, 0)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]&31)
+	}
+	return expanded
+}
+
+func bech32Checksum(hrp string, values []byte) []byte {
+	polymod := bech32Polymod(append(append(bech32HRPExpand(hrp), values...), 0, 0, 0, 0, 0, 0)) ^ bech32mConstant
+	checksum := make([]byte, 6)
+	for i := range checksum {
+		checksum[i] = byte(polymod>>(5*(5-i))) & 31
+	}
+	return checksum
+}
+
+// convertBits regroups a sequence of fromBits-wide values into toBits-wide values
+func convertBits(data []byte, fromBits uint, toBits uint, pad bool) ([]byte, error) {
+	var output []byte
+	acc, bits := uint32(0), uint(0)
+	maxValue := uint32(1)<<toBits - 1
+	maxAcc := uint32(1)<<(fromBits+toBits-1) - 1
+	for _, value := range data {
+		acc = (acc<<fromBits | uint32(value)) & maxAcc
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			output = append(output, byte(acc>>bits&maxValue))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			output = append(output, byte(acc<<(toBits-bits)&maxValue))
+		}
+	} else if bits >= fromBits || acc<<(toBits-bits)&maxValue != 0 {
+		return nil, fmt.Errorf("invalid padding of bech32 data")
+	}
+	return output, nil
+}
@@ -0,0 +1,98 @@
+package container
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/d347h-eth/aesgcm/internal/domain"
+)
+
+// binaryMagic prefixes the binary container, the last byte is the version of the layout
+var binaryMagic = []byte{'A', 'E', 'S', 'G', 'C', 'M', 0x00, 0x01}
+
+// marshalBinary packs DTO into the compact binary layout:
+// magic | uvarint header length | header | uvarint salt length | salt |
+// uint32 iterations | uint16 key length | uvarint nonce length | nonce | ciphertext
+func marshalBinary(dto *domain.DTO) ([]byte, error) {
+	if dto.KeyDerivation.Iterations < 0 || uint64(dto.KeyDerivation.Iterations) > math.MaxUint32 {
+		return nil, fmt.Errorf("key derivation iterations out of range: %d", dto.KeyDerivation.Iterations)
+	}
+	if dto.KeyDerivation.Length < 0 || dto.KeyDerivation.Length > math.MaxUint16 {
+		return nil, fmt.Errorf("key derivation length out of range: %d", dto.KeyDerivation.Length)
+	}
+	buffer := bytes.NewBuffer(append([]byte{}, binaryMagic...))
+	writeChunk(buffer, dto.Header)
+	writeChunk(buffer, dto.KeyDerivation.Salt)
+	buffer.Write(binary.BigEndian.AppendUint32(nil, uint32(dto.KeyDerivation.Iterations)))
+	buffer.Write(binary.BigEndian.AppendUint16(nil, uint16(dto.KeyDerivation.Length)))
+	writeChunk(buffer, dto.Nonce)
+	buffer.Write(dto.Ciphertext)
+	return buffer.Bytes(), nil
+}
+
+func unmarshalBinary(data []byte) (*domain.DTO, error) {
+	if !bytes.HasPrefix(data, binaryMagic[:len(binaryMagic)-1]) {
+		return nil, fmt.Errorf("missing binary container magic")
+	}
+	if len(data) < len(binaryMagic) {
+		return nil, fmt.Errorf("binary container is truncated")
+	}
+	if data[len(binaryMagic)-1] != binaryMagic[len(binaryMagic)-1] {
+		return nil, fmt.Errorf("unsupported binary container version: %d", data[len(binaryMagic)-1])
+	}
+	reader := bytes.NewReader(data[len(binaryMagic):])
+	header, err := readChunk(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	salt, err := readChunk(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read salt: %w", err)
+	}
+	var iterations uint32
+	var length uint16
+	if err := binary.Read(reader, binary.BigEndian, &iterations); err != nil {
+		return nil, fmt.Errorf("failed to read key derivation iterations: %w", err)
+	}
+	if err := binary.Read(reader, binary.BigEndian, &length); err != nil {
+		return nil, fmt.Errorf("failed to read key derivation length: %w", err)
+	}
+	if uint64(iterations) > uint64(math.MaxInt) {
+		return nil, fmt.Errorf("key derivation iterations out of range: %d", iterations)
+	}
+	nonce, err := readChunk(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read nonce: %w", err)
+	}
+	ciphertext := make([]byte, reader.Len())
+	if _, err := io.ReadFull(reader, ciphertext); err != nil {
+		return nil, fmt.Errorf("failed to read ciphertext: %w", err)
+	}
+	return domain.NewDTO(header, salt, int(iterations), int(length), nonce, ciphertext), nil
+}
+
+func writeChunk(buffer *bytes.Buffer, chunk []byte) {
+	buffer.Write(binary.AppendUvarint(nil, uint64(len(chunk))))
+	buffer.Write(chunk)
+}
+
+func readChunk(reader *bytes.Reader) ([]byte, error) {
+	length, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return nil, err
+	}
+	if length > uint64(reader.Len()) {
+		return nil, fmt.Errorf("chunk length exceeds the input: %d", length)
+	}
+	if length == 0 {
+		return nil, nil
+	}
+	chunk := make([]byte, length)
+	if _, err := io.ReadFull(reader, chunk); err != nil {
+		return nil, err
+	}
+	return chunk, nil
+}
@@ -0,0 +1,65 @@
+package container
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/d347h-eth/aesgcm/internal/domain"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// cborMagic is the encoding of CBOR self-described tag (55799) which prefixes the container
+var cborMagic = []byte{0xd9, 0xd9, 0xf7}
+
+type (
+	// dtoCBOR is a proxy type that represents DTO as CBOR map with integer keys
+	dtoCBOR struct {
+		Header     []byte `cbor:"1,keyasint,omitempty"`
+		Salt       []byte `cbor:"2,keyasint"`
+		Iterations int    `cbor:"3,keyasint"`
+		Length     int    `cbor:"4,keyasint"`
+		Nonce      []byte `cbor:"5,keyasint"`
+		Ciphertext []byte `cbor:"6,keyasint"`
+	}
+)
+
+func marshalCBOR(dto *domain.DTO) ([]byte, error) {
+	encoder, err := cbor.CoreDetEncOptions().EncMode()
+	if err != nil {
+		return nil, err
+	}
+	data, err := encoder.Marshal(cbor.Tag{
+		Number: 55799,
+		Content: dtoCBOR{
+			Header:     dto.Header,
+			Salt:       dto.KeyDerivation.Salt,
+			Iterations: dto.KeyDerivation.Iterations,
+			Length:     dto.KeyDerivation.Length,
+			Nonce:      dto.Nonce,
+			Ciphertext: dto.Ciphertext,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while encoding to CBOR: %w", err)
+	}
+	return data, nil
+}
+
+func unmarshalCBOR(data []byte) (*domain.DTO, error) {
+	if !bytes.HasPrefix(data, cborMagic) {
+		return nil, fmt.Errorf("missing CBOR self-described tag")
+	}
+	decoder, err := cbor.DecOptions{
+		DupMapKey:         cbor.DupMapKeyEnforcedAPF,
+		ExtraReturnErrors: cbor.ExtraDecErrorUnknownField,
+	}.DecMode()
+	if err != nil {
+		return nil, err
+	}
+	proxy := dtoCBOR{}
+	if err := decoder.Unmarshal(data[len(cborMagic):], &proxy); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal CBOR: %w", err)
+	}
+	return domain.NewDTO(proxy.Header, proxy.Salt, proxy.Iterations, proxy.Length, proxy.Nonce, proxy.Ciphertext), nil
+}
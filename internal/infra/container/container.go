@@ -0,0 +1,271 @@
+package container
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/d347h-eth/aesgcm/internal/domain"
+)
+
+// FORMAT_JSON is plain JSON with Base64 encoded fields
+const FORMAT_JSON = "json"
+
+// FORMAT_JSON_BASE64 is JSON wrapped with additional Base64 encoding
+const FORMAT_JSON_BASE64 = "json-b64"
+
+// FORMAT_CBOR is CBOR map with integer keys
+const FORMAT_CBOR = "cbor"
+
+// FORMAT_BINARY is the fixed binary layout
+const FORMAT_BINARY = "binary"
+
+// Formats lists all supported output formats
+var Formats = []string{FORMAT_JSON, FORMAT_JSON_BASE64, FORMAT_CBOR, FORMAT_BINARY}
+
+type (
+	// Container is a component responsible for serialization of DTO into the output format
+	// and for recognizing the format of the input
+	Container struct {
+		cfg Config
+	}
+
+	// Config ...
+	Config struct {
+		// Format is the output format
+		Format string
+		// ArmorEnabled wraps the output into PEM-like ASCII armor
+		ArmorEnabled bool
+		// ArmorComment is an optional "Comment" header of the armor
+		ArmorComment string
+		// Encoding is an optional text encoding with checksums wrapping the output
+		Encoding string
+		// QREncoding is the encoding of QR code payloads (none, base45, base32)
+		QREncoding string
+		// ECCParity is the number of Reed-Solomon parity bytes per 255-byte codeword added to armored
+		// or Base32 encoded output, zero disables error correction
+		ECCParity int
+	}
+)
+
+// NewContainer ...
+func NewContainer(cfg Config) *Container {
+	return &Container{cfg}
+}
+
+// Marshal serializes DTO into the configured output format
+func (c Container) Marshal(dto *domain.DTO) ([]byte, error) {
+	switch {
+	case c.cfg.ArmorEnabled && c.cfg.Encoding != "":
+		return nil, fmt.Errorf("ASCII armor can't be combined with a text encoding")
+	case c.cfg.ECCParity > 0 && !c.cfg.ArmorEnabled && c.cfg.Encoding != ENCODING_BASE32:
+		return nil, fmt.Errorf("error correction requires ASCII armor or Base32 encoding")
+	case c.cfg.ArmorEnabled:
+		return c.marshalArmored(dto)
+	case c.cfg.Encoding != "":
+		data, err := c.marshalInner(dto)
+		if err != nil {
+			return nil, err
+		}
+		if c.cfg.ECCParity > 0 {
+			layout, err := newECCLayout(c.cfg.ECCParity, len(data))
+			if err != nil {
+				return nil, err
+			}
+			return []byte(blockEncodings[ENCODING_BASE32].encodeBlocks(layout.protect(data), layout.String())), nil
+		}
+		return encodeText(c.cfg.Encoding, data)
+	}
+	return c.marshalFormat(dto)
+}
+
+// MarshalQR serializes DTO for a QR code, with QR encoding configured the binary container is encoded
+// with the characters of QR alphanumeric mode, which stores 5.5 bits per character instead of 8
+func (c Container) MarshalQR(dto *domain.DTO) ([]byte, error) {
+	var encode func(data []byte) string
+	switch c.cfg.QREncoding {
+	case QR_ENCODING_NONE, "":
+		return c.Marshal(dto)
+	case QR_ENCODING_BASE45:
+		encode = func(data []byte) string {
+			return base45Prefix + encodeBase45(data)
+		}
+	case QR_ENCODING_BASE32:
+		encode = func(data []byte) string {
+			return qrBase32Prefix + base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(data)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported QR code encoding: %q", c.cfg.QREncoding)
+	}
+	data, err := marshalBinary(dto)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(encode(data)), nil
+}
+
+// marshalInner serializes DTO for a text wrapping, which makes Base64 wrapping of JSON redundant
+func (c Container) marshalInner(dto *domain.DTO) ([]byte, error) {
+	inner := c
+	if inner.cfg.Format == FORMAT_JSON_BASE64 || inner.cfg.Format == "" {
+		inner.cfg.Format = FORMAT_JSON
+	}
+	return inner.marshalFormat(dto)
+}
+
+// MarshalLines serializes DTO into ASCII armor with numbered body lines
+// protected by per-line checksums, which is suitable for printing and manual transcription
+func (c Container) MarshalLines(dto *domain.DTO) ([]string, error) {
+	armored, err := c.marshalArmored(dto)
+	if err != nil {
+		return nil, err
+	}
+	return numberLines(armored), nil
+}
+
+func (c Container) marshalArmored(dto *domain.DTO) ([]byte, error) {
+	data, err := c.marshalInner(dto)
+	if err != nil {
+		return nil, err
+	}
+	headers := map[string]string{"Format-Version": ARMOR_VERSION}
+	if c.cfg.ArmorComment != "" {
+		headers["Comment"] = strings.ReplaceAll(c.cfg.ArmorComment, "\n", " ")
+	}
+	if c.cfg.ECCParity > 0 {
+		layout, err := newECCLayout(c.cfg.ECCParity, len(data))
+		if err != nil {
+			return nil, err
+		}
+		headers[eccHeader] = layout.String()
+		data = layout.protect(data)
+	}
+	return armor(data, headers), nil
+}
+
+func (c Container) marshalFormat(dto *domain.DTO) ([]byte, error) {
+	switch c.cfg.Format {
+	case FORMAT_JSON:
+		return marshalJSON(dto)
+	case FORMAT_JSON_BASE64, "":
+		outputJSON, err := marshalJSON(dto)
+		if err != nil {
+			return nil, err
+		}
+		// wrap output JSON with additional Base64 encoding
+		outputData := make([]byte, base64.StdEncoding.EncodedLen(len(outputJSON)))
+		base64.StdEncoding.Encode(outputData, outputJSON)
+		return outputData, nil
+	case FORMAT_CBOR:
+		return marshalCBOR(dto)
+	case FORMAT_BINARY:
+		return marshalBinary(dto)
+	default:
+		return nil, fmt.Errorf("unsupported output format: %q", c.cfg.Format)
+	}
+}
+
+// Unmarshal recognizes the format of the input and deserializes DTO from it
+func (c Container) Unmarshal(data []byte) (*domain.DTO, error) {
+	return unmarshal(data, 0)
+}
+
+// Repair corrects damaged text protected with Reed-Solomon parity and reports the repaired lines,
+// the input without parity is returned as is
+func (c Container) Repair(data []byte) ([]byte, []domain.Repair, error) {
+	repaired, repairs, ok, err := repairText(data)
+	if !ok {
+		return data, nil, nil
+	}
+	return repaired, repairs, err
+}
+
+// maxNestingDepth limits recursion over nested wrappings
+const maxNestingDepth = 4
+
+func unmarshal(data []byte, depth int) (*domain.DTO, error) {
+	if depth > maxNestingDepth {
+		return nil, fmt.Errorf("too many nested encodings")
+	}
+	// binary containers are recognized by their magic bytes
+	switch {
+	case bytes.HasPrefix(data, cborMagic):
+		return unmarshalCBOR(data)
+	case bytes.HasPrefix(data, binaryMagic[:len(binaryMagic)-1]):
+		return unmarshalBinary(data)
+	}
+	// text protected with error correction code is repaired first
+	if repaired, _, ok, err := repairText(data); ok {
+		if err != nil {
+			return nil, err
+		}
+		return unmarshal(repaired, depth+1)
+	}
+	// armored block may be embedded in surrounding text
+	if bytes.Contains(data, armorBegin) {
+		body, _, err := dearmor(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dearmor input: %w", err)
+		}
+		return unmarshal(body, depth+1)
+	}
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case len(trimmed) == 0:
+		return nil, fmt.Errorf("input is empty")
+	case trimmed[0] == '{': // raw JSON
+		dto := &domain.DTO{}
+		if err := json.Unmarshal(trimmed, dto); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+		}
+		return dto, nil
+	case bytes.HasPrefix(trimmed, []byte("-----BEGIN ")):
+		return nil, fmt.Errorf("unrecognized ASCII armor type")
+	case !utf8.Valid(trimmed):
+		return nil, fmt.Errorf("unrecognized binary input format")
+	}
+	// text encodings are recognized by their prefixes
+	if decoded, ok, err := decodeText(data); ok {
+		if err != nil {
+			return nil, err
+		}
+		return unmarshal(decoded, depth+1)
+	}
+	// Base64 wrapping, line breaks and indentation are tolerated
+	decoded, err := decodeBase64(stripWhitespace(trimmed))
+	if err != nil {
+		return nil, fmt.Errorf("unrecognized input format: %w", err)
+	}
+	return unmarshal(decoded, depth+1)
+}
+
+func marshalJSON(dto *domain.DTO) ([]byte, error) {
+	outputJSON, err := json.Marshal(dto)
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while encoding to JSON: %w", err)
+	}
+	return outputJSON, nil
+}
+
+func decodeBase64(data []byte) ([]byte, error) {
+	decoded := make([]byte, base64.StdEncoding.DecodedLen(len(data)))
+	n, err := base64.StdEncoding.Decode(decoded, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode Base64: %w", err)
+	}
+	return decoded[:n], nil
+}
+
+func stripWhitespace(data []byte) []byte {
+	return bytes.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\r', '\n':
+			return -1
+		}
+		return r
+	}, data)
+}
@@ -0,0 +1,569 @@
+package container
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/d347h-eth/aesgcm/internal/domain"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+func TestUnmarshalDetectsFormat(t *testing.T) {
+	dto := domain.NewDTO([]byte(`{"version":1}`), []byte("salt"), 1000, 32, []byte("nonce"), []byte("ciphertext"))
+	plain, err := NewContainer(Config{Format: FORMAT_JSON}).Marshal(dto)
+	if err != nil {
+		t.Fatalf("failed to marshal JSON: %s", err)
+	}
+	wrapped, err := NewContainer(Config{}).Marshal(dto)
+	if err != nil {
+		t.Fatalf("failed to marshal Base64: %s", err)
+	}
+	var lines []string
+	for i := 0; i < len(wrapped); i += 20 {
+		end := i + 20
+		if end > len(wrapped) {
+			end = len(wrapped)
+		}
+		lines = append(lines, "  "+string(wrapped[i:end]))
+	}
+	cborData, err := NewContainer(Config{Format: FORMAT_CBOR}).Marshal(dto)
+	if err != nil {
+		t.Fatalf("failed to marshal CBOR: %s", err)
+	}
+	binaryData, err := NewContainer(Config{Format: FORMAT_BINARY}).Marshal(dto)
+	if err != nil {
+		t.Fatalf("failed to marshal binary: %s", err)
+	}
+	inputs := map[string][]byte{
+		"cbor":          cborData,
+		"binary":        binaryData,
+		"json":          plain,
+		"indented json": append([]byte("\n\t"), plain...),
+		"base64":        wrapped,
+		"wrapped":       []byte(strings.Join(lines, "\r\n") + "\n"),
+	}
+	container := NewContainer(Config{})
+	for name, input := range inputs {
+		decoded, err := container.Unmarshal(input)
+		if err != nil {
+			t.Fatalf("failed to unmarshal %s input: %s", name, err)
+		}
+		if !bytes.Equal(decoded.Ciphertext, dto.Ciphertext) || !bytes.Equal(decoded.Header, dto.Header) ||
+			!bytes.Equal(decoded.KeyDerivation.Salt, dto.KeyDerivation.Salt) || !bytes.Equal(decoded.Nonce, dto.Nonce) ||
+			decoded.KeyDerivation.Iterations != dto.KeyDerivation.Iterations ||
+			decoded.KeyDerivation.Length != dto.KeyDerivation.Length {
+			t.Fatalf("decoded %s DTO doesn't match: got %+v", name, decoded)
+		}
+	}
+	if _, err := container.Unmarshal([]byte("not a container")); err == nil {
+		t.Fatalf("expected an error for unrecognized input")
+	}
+}
+
+func TestUnmarshalBinaryTruncated(t *testing.T) {
+	dto := domain.NewDTO([]byte(`{"version":1}`), []byte("salt"), 1000, 32, []byte("nonce"), []byte("ciphertext"))
+	data, err := marshalBinary(dto)
+	if err != nil {
+		t.Fatalf("failed to marshal binary: %s", err)
+	}
+	// every truncation before the ciphertext has to be reported instead of panicking
+	for length := len(binaryMagic) - 1; length < len(data)-len(dto.Ciphertext); length++ {
+		if _, err := NewContainer(Config{}).Unmarshal(data[:length]); err == nil {
+			t.Fatalf("truncation to %d bytes hasn't been detected", length)
+		}
+	}
+}
+
+func TestArmor(t *testing.T) {
+	if checksum := crc24([]byte("123456789")); checksum != 0x21CF02 {
+		t.Fatalf("unexpected CRC24 checksum: got %06X, want %06X", checksum, 0x21CF02)
+	}
+	dto := domain.NewDTO(nil, []byte("salt"), 1000, 32, []byte("nonce"), bytes.Repeat([]byte("ciphertext"), 20))
+	armored, err := NewContainer(Config{Format: FORMAT_CBOR, ArmorEnabled: true, ArmorComment: "backup"}).Marshal(dto)
+	if err != nil {
+		t.Fatalf("failed to marshal armor: %s", err)
+	}
+	for _, line := range strings.Split(string(armored), "\n") {
+		if len(line) > armorLineLength {
+			t.Fatalf("armor line is too long: %q", line)
+		}
+	}
+	if !bytes.Contains(armored, []byte("Comment: backup\n")) {
+		t.Fatalf("armor comment header is missing:\n%s", armored)
+	}
+	// armor pasted into a quoted email reply
+	email := "Hi,\n\nhere is the backup:\n\n> " +
+		strings.ReplaceAll(string(armored), "\n", "\n> ") + "\nRegards\n"
+	decoded, err := NewContainer(Config{}).Unmarshal([]byte(email))
+	if err != nil {
+		t.Fatalf("failed to unmarshal armor: %s", err)
+	}
+	if !bytes.Equal(decoded.Ciphertext, dto.Ciphertext) {
+		t.Fatalf("decoded armored DTO doesn't match")
+	}
+	// a damaged character has to be caught by the checksum
+	lines := strings.Split(string(armored), "\n")
+	body := []byte(lines[4])
+	body[10] ^= 'A' ^ 'B'
+	lines[4] = string(body)
+	if _, err := NewContainer(Config{}).Unmarshal([]byte(strings.Join(lines, "\n"))); err == nil {
+		t.Fatalf("damaged armor hasn't been detected")
+	}
+}
+
+func TestEncodings(t *testing.T) {
+	dto := domain.NewDTO([]byte(`{"version":1}`), []byte("salt"), 1000, 32, []byte("nonce"), bytes.Repeat([]byte("ciphertext"), 10))
+	for _, encoding := range Encodings {
+		encoded, err := NewContainer(Config{Format: FORMAT_BINARY, Encoding: encoding}).Marshal(dto)
+		if err != nil {
+			t.Fatalf("failed to marshal %s: %s", encoding, err)
+		}
+		container := NewContainer(Config{})
+		decoded, err := container.Unmarshal(encoded)
+		if err != nil {
+			t.Fatalf("failed to unmarshal %s: %s", encoding, err)
+		}
+		if !bytes.Equal(decoded.Ciphertext, dto.Ciphertext) {
+			t.Fatalf("decoded %s DTO doesn't match", encoding)
+		}
+		if encoding == ENCODING_WORDS { // mistyped words are covered by TestWords
+			continue
+		}
+		// a single mistyped character has to be reported at its position
+		position := bytes.LastIndexByte(encoded, '\n') - 7
+		damaged := append([]byte{}, encoded...)
+		if damaged[position] == 'q' || damaged[position] == '2' {
+			damaged[position] = '3'
+		} else {
+			damaged[position] = '2'
+		}
+		_, err = container.Unmarshal(damaged)
+		transcriptionErr, ok := err.(*TranscriptionError)
+		if !ok {
+			t.Fatalf("expected transcription error for %s, got: %v", encoding, err)
+		}
+		if transcriptionErr.Position != position || transcriptionErr.Suggestion != string(encoded[position]) {
+			t.Fatalf("unexpected transcription error for %s: %s (want position %d, %q)",
+				encoding, err, position, encoded[position])
+		}
+	}
+}
+
+func TestBech32(t *testing.T) {
+	// valid Bech32m strings of BIP 350
+	for _, vector := range []string{"a1lqfn3a", "abcdef1l7aum6echk45nj3s0wdvt2fg8x9yrzpqzd3ryx"} {
+		separator := strings.LastIndexByte(vector, '1')
+		data, err := decodeBech32Segment([]byte(vector), vector, make([]int, len(vector)), vector[:separator])
+		if err != nil {
+			t.Fatalf("failed to decode %q: %s", vector, err)
+		}
+		if encoded, _ := encodeBech32(vector[:separator], data); encoded != vector {
+			t.Fatalf("unexpected encoding of %q: %q", vector, encoded)
+		}
+	}
+
+	dto := domain.NewDTO([]byte(`{"version":1}`), []byte("salt"), 1000, 32, []byte("nonce"), bytes.Repeat([]byte("ciphertext"), 30))
+	encoded, err := NewContainer(Config{Format: FORMAT_BINARY, Encoding: ENCODING_BECH32}).Marshal(dto)
+	if err != nil {
+		t.Fatalf("failed to marshal bech32: %s", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(encoded)), "\n")
+	if len(lines) < 3 {
+		t.Fatalf("expected several segments, got %d", len(lines))
+	}
+	for _, line := range lines {
+		if len(line) > bech32MaxLength {
+			t.Fatalf("bech32 segment exceeds %d characters: %q", bech32MaxLength, line)
+		}
+	}
+	// reordered and missing segments are detected
+	swapped := append([]string{lines[1], lines[0]}, lines[2:]...)
+	for name, damaged := range map[string][]string{"swapped": swapped, "missing": lines[:len(lines)-1]} {
+		if _, err := NewContainer(Config{}).Unmarshal([]byte(strings.Join(damaged, "\n"))); err == nil {
+			t.Fatalf("%s segments haven't been detected", name)
+		}
+	}
+}
+
+func TestMarshalLines(t *testing.T) {
+	dto := domain.NewDTO(nil, []byte("salt"), 1000, 32, []byte("nonce"), bytes.Repeat([]byte("ciphertext"), 20))
+	container := NewContainer(Config{Format: FORMAT_BINARY, ArmorEnabled: true})
+	lines, err := container.MarshalLines(dto)
+	if err != nil {
+		t.Fatalf("failed to marshal lines: %s", err)
+	}
+	decoded, err := container.Unmarshal([]byte(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatalf("failed to unmarshal numbered lines: %s", err)
+	}
+	if !bytes.Equal(decoded.Ciphertext, dto.Ciphertext) {
+		t.Fatalf("decoded DTO doesn't match")
+	}
+	lines[4] = strings.Replace(lines[4], "Y", "X", 1)
+	if _, err := container.Unmarshal([]byte(strings.Join(lines, "\n"))); err == nil ||
+		!strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("expected transcription error in line 2, got: %v", err)
+	}
+}
+
+func TestUR(t *testing.T) {
+	// reference vector of BCR-2020-005: 50 bytes generated by Xoshiro256** seeded with "Wolf"
+	rng := newXoshiro256(sha256.Sum256([]byte("Wolf")))
+	message := make([]byte, 50)
+	for i := range message {
+		message[i] = byte(rng.nextInt(0, 255))
+	}
+	encoded, err := encodeUR(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "ur:bytes/hdeymejtswhhylkepmykhhtsytsnoyoyaxaedsuttydmmhhpktpmsrjtgwdpfnsboxgwlbaawzuefywkdplrsrjynbvygabwjldapfcsdwkbrkch\n"
+	if string(encoded) != expected {
+		t.Fatalf("unexpected UR: %s", encoded)
+	}
+
+	dto := domain.NewDTO(nil, []byte("salt"), 1000, 32, []byte("nonce"), bytes.Repeat([]byte("ciphertext"), 100))
+	encoded, err = NewContainer(Config{Format: FORMAT_BINARY, Encoding: ENCODING_UR}).Marshal(dto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Fields(strings.ToUpper(string(encoded)))
+	if len(parts) < 3 {
+		t.Fatalf("expected multipart UR, got %d parts", len(parts))
+	}
+
+	// the first part is lost, a fountain-coded part mixing it with the others replaces it
+	var first urPart
+	if err := cbor.Unmarshal(mustDecodeBytewords(t, parts[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	fragments := [][]byte{first.Data}
+	for _, part := range parts[1:] {
+		var decoded urPart
+		if err := cbor.Unmarshal(mustDecodeBytewords(t, part), &decoded); err != nil {
+			t.Fatal(err)
+		}
+		fragments = append(fragments, decoded.Data)
+	}
+	mixed := first
+	for seqNum := uint32(first.SeqLen + 1); ; seqNum++ {
+		indexes := urChooseFragments(seqNum, first.SeqLen, first.Checksum)
+		if indexes[0] != 0 {
+			continue
+		}
+		mixed.SeqNum = seqNum
+		mixed.Data = make([]byte, len(first.Data))
+		for _, index := range indexes {
+			for i := range mixed.Data {
+				mixed.Data[i] ^= fragments[index][i]
+			}
+		}
+		break
+	}
+	part, err := cbor.Marshal(mixed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fountainPart := fmt.Sprintf("ur:bytes/%d-%d/%s", mixed.SeqNum, mixed.SeqLen, encodeBytewords(part))
+
+	container := NewContainer(Config{})
+	received := append([]string{fountainPart}, parts[1:]...)
+	received[1], received[len(received)-1] = received[len(received)-1], received[1]
+	decoded, err := container.Unmarshal([]byte(strings.Join(received, "\n")))
+	if err != nil {
+		t.Fatalf("failed to unmarshal multipart UR: %s", err)
+	}
+	if !bytes.Equal(decoded.Ciphertext, dto.Ciphertext) {
+		t.Fatal("decoded DTO doesn't match")
+	}
+
+	_, err = container.Unmarshal([]byte(strings.Join(parts[1:], " ")))
+	if err == nil || !strings.Contains(err.Error(), "missing UR fragments 1 of") {
+		t.Fatalf("expected missing fragment error, got: %v", err)
+	}
+
+	// a crafted part declaring 2^31-1 fragments mustn't be allocated
+	crafted, err := cbor.Marshal(urPart{SeqNum: 1, SeqLen: math.MaxInt32, MessageLen: 10, Data: []byte{1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = container.Unmarshal([]byte(fmt.Sprintf("ur:bytes/1-%d/%s", math.MaxInt32, encodeBytewords(crafted))))
+	if err == nil || !strings.Contains(err.Error(), "invalid UR sequence length") {
+		t.Fatalf("expected invalid sequence length error, got: %v", err)
+	}
+}
+
+func mustDecodeBytewords(t *testing.T, ur string) []byte {
+	t.Helper()
+	words := strings.ToLower(ur[strings.LastIndexByte(ur, '/')+1:])
+	data, err := decodeBytewords([]byte(words), words, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestURReference(t *testing.T) {
+	// multipart vector of BCR-2020-005: 256 bytes generated by Xoshiro256** seeded with "Wolf",
+	// split into 30-byte fragments by the reference encoder, parts after 9 are fountain-coded
+	parts := map[int]string{
+		4:  "ur:bytes/4-9/lpaaascfadaxcywenbpljkhdcasotkhemthydawydtaxneurlkosgwcekonertkbrlwmplssjtammdplolsbrdzcrtas",
+		5:  "ur:bytes/5-9/lpahascfadaxcywenbpljkhdcatbbdfmssrkzmcwnezelennjpfzbgmuktrhtejscktelgfpdlrkfyfwdajldejokbwf",
+		6:  "ur:bytes/6-9/lpamascfadaxcywenbpljkhdcackjlhkhybssklbwefectpfnbbectrljectpavyrolkzczcpkmwidmwoxkilghdsowp",
+		7:  "ur:bytes/7-9/lpatascfadaxcywenbpljkhdcavszmwnjkwtclrtvaynhpahrtoxmwvwatmedibkaegdosftvandiodagdhthtrlnnhy",
+		8:  "ur:bytes/8-9/lpayascfadaxcywenbpljkhdcadmsponkkbbhgsoltjntegepmttmoonftnbuoiyrehfrtsabzsttorodklubbuyaetk",
+		9:  "ur:bytes/9-9/lpasascfadaxcywenbpljkhdcajskecpmdckihdyhphfotjojtfmlnwmadspaxrkytbztpbauotbgtgtaeaevtgavtny",
+		12: "ur:bytes/12-9/lpbnascfadaxcywenbpljkhdcarllaluzmdmgstospeyiefmwejlwtpedamktksrvlcygmzemovovllarodtmtbnptrs",
+		13: "ur:bytes/13-9/lpbtascfadaxcywenbpljkhdcamtkgtpknghchchyketwsvwgwfdhpgmgtylctotzopdrpayoschcmhplffziachrfgd",
+		14: "ur:bytes/14-9/lpbaascfadaxcywenbpljkhdcapazewnvonnvdnsbyleynwtnsjkjndeoldydkbkdslgjkbbkortbelomueekgvstegt",
+		15: "ur:bytes/15-9/lpbsascfadaxcywenbpljkhdcaynmhpddpzmversbdqdfyrehnqzlugmjzmnmtwmrouohtstgsbsahpawkditkckynwt",
+		16: "ur:bytes/16-9/lpbeascfadaxcywenbpljkhdcawygekobamwtlihsnpalnsghenskkiynthdzotsimtojetprsttmukirlrsbtamjtpd",
+		17: "ur:bytes/17-9/lpbyascfadaxcywenbpljkhdcamklgftaxykpewyrtqzhydntpnytyisincxmhtbceaykolduortotiaiaiafhiaoyce",
+	}
+	rng := newXoshiro256(sha256.Sum256([]byte("Wolf")))
+	message := make([]byte, 256)
+	for i := range message {
+		message[i] = byte(rng.nextInt(0, 255))
+	}
+
+	// fragments 1-3 are recovered only from the fountain-coded parts, which requires the same fragment choice
+	var received []string
+	for _, seqNum := range []int{17, 4, 12, 5, 13, 6, 14, 7, 15, 8, 16, 9} {
+		received = append(received, parts[seqNum])
+	}
+	decoded, err := decodeURText([]byte(strings.Join(received, "\n")))
+	if err != nil {
+		t.Fatalf("failed to decode reference parts: %s", err)
+	}
+	if !bytes.Equal(decoded, message) {
+		t.Fatal("decoded reference message doesn't match")
+	}
+	_, err = decodeURText([]byte(strings.Join([]string{parts[4], parts[5], parts[6], parts[7], parts[8], parts[9]}, " ")))
+	if err == nil || !strings.Contains(err.Error(), "missing UR fragments 1, 2, 3 of 9") {
+		t.Fatalf("expected missing fragments error, got: %v", err)
+	}
+}
+
+func TestMarshalQR(t *testing.T) {
+	// RFC 9285 examples
+	for input, expected := range map[string]string{"AB": "BB8", "Hello!!": "%69 VD92EX0", "base-45": "UJCLQE7W581"} {
+		if encoded := encodeBase45([]byte(input)); encoded != expected {
+			t.Fatalf("unexpected Base45 of %q: %q", input, encoded)
+		}
+		if decoded, err := decodeBase45(nil, expected, 0); err != nil || string(decoded) != input {
+			t.Fatalf("unexpected Base45 decoding of %q: %q, %v", expected, decoded, err)
+		}
+	}
+
+	dto := domain.NewDTO([]byte(`{"version":1}`), []byte("salt"), 1000, 32, []byte("nonce"), bytes.Repeat([]byte("ciphertext"), 10))
+	for _, encoding := range []string{QR_ENCODING_BASE45, QR_ENCODING_BASE32} {
+		encoded, err := NewContainer(Config{QREncoding: encoding}).MarshalQR(dto)
+		if err != nil {
+			t.Fatalf("failed to marshal %s: %s", encoding, err)
+		}
+		for _, char := range encoded {
+			if !strings.ContainsRune(base45Alphabet, rune(char)) {
+				t.Fatalf("%s payload contains non-alphanumeric character %q", encoding, char)
+			}
+		}
+		decoded, err := NewContainer(Config{}).Unmarshal(encoded)
+		if err != nil {
+			t.Fatalf("failed to unmarshal %s: %s", encoding, err)
+		}
+		if !bytes.Equal(decoded.Ciphertext, dto.Ciphertext) {
+			t.Fatalf("decoded %s DTO doesn't match", encoding)
+		}
+	}
+}
+
+func TestReedSolomon(t *testing.T) {
+	message := []byte("The quick brown fox jumps over the lazy dog")
+	codeword := rsEncode(message, 10)
+	for name, damage := range map[string]struct {
+		errors   []int
+		erasures []int
+	}{
+		"errors":              {errors: []int{0, 7, 20, 41, 52}},
+		"erasures":            {erasures: []int{1, 2, 3, 4, 5, 30, 31, 32, 33, 50}},
+		"errors and erasures": {errors: []int{9, 40}, erasures: []int{10, 11, 12, 13, 14, 15}},
+	} {
+		damaged := append([]byte(nil), codeword...)
+		for _, position := range append(damage.errors, damage.erasures...) {
+			damaged[position] ^= 0x5a
+		}
+		changed, err := rsDecode(damaged, 10, damage.erasures)
+		if err != nil {
+			t.Fatalf("failed to correct %s: %s", name, err)
+		}
+		if !bytes.Equal(damaged, codeword) || len(changed) != len(damage.errors)+len(damage.erasures) {
+			t.Fatalf("%s aren't corrected: %q, changed %v", name, damaged, changed)
+		}
+	}
+	damaged := append([]byte(nil), codeword...)
+	for _, position := range []int{0, 1, 2, 3, 4, 5} {
+		damaged[position] ^= 0x5a
+	}
+	if _, err := rsDecode(damaged, 10, nil); err == nil && bytes.Equal(damaged, codeword) {
+		t.Fatalf("too many errors have been corrected")
+	}
+}
+
+func TestErrorCorrection(t *testing.T) {
+	dto := domain.NewDTO(nil, []byte("salt"), 1000, 32, []byte("nonce"), bytes.Repeat([]byte("ciphertext"), 100))
+	armored := NewContainer(Config{Format: FORMAT_BINARY, ArmorEnabled: true, ECCParity: 32})
+	lines, err := armored.MarshalLines(dto)
+	if err != nil {
+		t.Fatalf("failed to marshal lines: %s", err)
+	}
+	text, err := armored.Marshal(dto)
+	if err != nil {
+		t.Fatalf("failed to marshal armor: %s", err)
+	}
+	base32Text, err := NewContainer(Config{Format: FORMAT_BINARY, Encoding: ENCODING_BASE32, ECCParity: 32}).Marshal(dto)
+	if err != nil {
+		t.Fatalf("failed to marshal Base32: %s", err)
+	}
+	if _, err := NewContainer(Config{Encoding: ENCODING_HEX, ECCParity: 32}).Marshal(dto); err == nil {
+		t.Fatalf("error correction is accepted with hex encoding")
+	}
+
+	armorLines := strings.Split(string(text), "\n")
+	armorLines[5] = strings.Repeat("#", len(armorLines[5]))                      // smudged line
+	armorLines[9] = strings.Replace(armorLines[9], armorLines[9][10:11], "+", 1) // typo
+	// the 2nd numbered line is lost and the 4th one is mistyped
+	numbered := append(append([]string(nil), lines[:5]...), lines[6:]...)
+	numbered[6] = numbered[6][:10] + string(numbered[6][10]^1) + numbered[6][11:]
+	base32Lines := strings.Split(string(base32Text), "\n")
+	base32Lines[2] = strings.ToLower(base32Lines[2][:20]) + "0" + base32Lines[2][21:]
+
+	for name, input := range map[string]struct {
+		text  string
+		lines []int
+	}{
+		"armor":          {strings.Join(armorLines, "\n"), []int{2, 6}},
+		"numbered lines": {strings.Join(numbered, "\n"), []int{2, 4}},
+		"base32":         {strings.Join(base32Lines, "\n"), []int{2}},
+	} {
+		repaired, repairs, err := armored.Repair([]byte(input.text))
+		if err != nil {
+			t.Fatalf("failed to repair %s: %s", name, err)
+		}
+		var repairedLines []int
+		for _, repair := range repairs {
+			repairedLines = append(repairedLines, repair.Line)
+		}
+		if fmt.Sprint(repairedLines) != fmt.Sprint(input.lines) {
+			t.Fatalf("unexpected repaired lines of %s: %v", name, repairs)
+		}
+		for _, data := range [][]byte{repaired, []byte(input.text)} {
+			decoded, err := armored.Unmarshal(data)
+			if err != nil {
+				t.Fatalf("failed to unmarshal repaired %s: %s", name, err)
+			}
+			if !bytes.Equal(decoded.Ciphertext, dto.Ciphertext) {
+				t.Fatalf("repaired %s DTO doesn't match", name)
+			}
+		}
+	}
+
+	for i := 4; i < 12; i++ {
+		armorLines[i] = ""
+	}
+	if _, _, err := armored.Repair([]byte(strings.Join(armorLines, "\n"))); err == nil {
+		t.Fatalf("expected an error on too damaged text")
+	}
+}
+
+func TestErrorCorrectionCrafted(t *testing.T) {
+	dto := domain.NewDTO(nil, []byte("salt"), 1000, 32, []byte("nonce"), bytes.Repeat([]byte("ciphertext"), 100))
+	armored := NewContainer(Config{Format: FORMAT_BINARY, ArmorEnabled: true, ECCParity: 32})
+	lines, err := armored.MarshalLines(dto)
+	if err != nil {
+		t.Fatalf("failed to marshal lines: %s", err)
+	}
+	text, err := armored.Marshal(dto)
+	if err != nil {
+		t.Fatalf("failed to marshal armor: %s", err)
+	}
+
+	// a line with a valid checksum and a number beyond the data is erased, the huge one overflows the offset
+	for _, number := range []int{len(lines), math.MaxInt/armorLineBytes + 2} {
+		crafted := append([]string(nil), lines...)
+		body := strings.Fields(crafted[6])[1]
+		crafted[6] = fmt.Sprintf("%d %s %s", number, body, lineChecksum(number, body))
+		decoded, err := armored.Unmarshal([]byte(strings.Join(crafted, "\n")))
+		if err != nil || !bytes.Equal(decoded.Ciphertext, dto.Ciphertext) {
+			t.Fatalf("failed to repair the line numbered %d: %v", number, err)
+		}
+	}
+
+	// the declared data length is limited before anything is allocated
+	for _, length := range []string{"9223372036854775000", "1000000000"} {
+		crafted := regexp.MustCompile(`RS=32/\d+`).ReplaceAllString(string(text), "RS=32/"+length)
+		if _, err := armored.Unmarshal([]byte(crafted)); err == nil {
+			t.Fatalf("expected an error for armor declaring %s bytes", length)
+		}
+		if _, err := armored.Unmarshal([]byte("AESGCM32:RS=16/" + length + "\nAAAA\n")); err == nil {
+			t.Fatalf("expected an error for Base32 declaring %s bytes", length)
+		}
+	}
+}
+
+func TestWords(t *testing.T) {
+	for length := 0; length < 40; length++ {
+		data := bytes.Repeat([]byte{0x80, 0}, length)[:length]
+		if decoded, err := decodeWordsText([]byte(encodeWords(data))); err != nil || !bytes.Equal(decoded, data) {
+			t.Fatalf("unexpected decoding of %d bytes: %x, %v", length, decoded, err)
+		}
+	}
+
+	dto := domain.NewDTO(nil, []byte("salt"), 1000, 32, []byte("nonce"), []byte("ciphertext"))
+	encoded, err := NewContainer(Config{Format: FORMAT_BINARY, Encoding: ENCODING_WORDS}).Marshal(dto)
+	if err != nil {
+		t.Fatalf("failed to marshal words: %s", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(encoded)), "\n")
+	if len(strings.Fields(lines[0])) != WORDS_PER_CHECKSUM+1 {
+		t.Fatalf("unexpected group length: %q", lines[0])
+	}
+	// words engraved on a plate are abbreviated to 4 letters and uppercase
+	var abbreviated []string
+	for _, word := range strings.Fields(string(encoded)) {
+		abbreviated = append(abbreviated, strings.ToUpper(word[:min(4, len(word))]))
+	}
+	container := NewContainer(Config{})
+	for _, input := range []string{string(encoded), strings.Join(abbreviated, " ")} {
+		decoded, err := container.Unmarshal([]byte(input))
+		if err != nil {
+			t.Fatalf("failed to unmarshal words: %s", err)
+		}
+		if !bytes.Equal(decoded.Ciphertext, dto.Ciphertext) {
+			t.Fatalf("decoded DTO doesn't match")
+		}
+	}
+
+	words := strings.Fields(lines[1])
+	typo := strings.Replace(string(encoded), words[2], words[2][:1]+"q"+words[2][2:], 1)
+	var transcriptionErr *TranscriptionError
+	if _, err := container.Unmarshal([]byte(typo)); !errors.As(err, &transcriptionErr) ||
+		transcriptionErr.Line != 2 || transcriptionErr.Column != len(words[0])+len(words[1])+3 {
+		t.Fatalf("expected transcription error in word 3 of line 2, got: %v", err)
+	}
+	words[1], words[3] = words[3], words[1]
+	swapped := strings.Replace(string(encoded), lines[1], strings.Join(words, " "), 1)
+	if _, err := container.Unmarshal([]byte(swapped)); !errors.As(err, &transcriptionErr) ||
+		transcriptionErr.Line != 2 || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected checksum mismatch in line 2, got: %v", err)
+	}
+	truncated := strings.Join(lines[:len(lines)-1], "\n")
+	if _, err := container.Unmarshal([]byte(truncated)); err == nil {
+		t.Fatalf("expected an error on truncated words")
+	}
+}
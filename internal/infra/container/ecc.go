@@ -0,0 +1,247 @@
+package container
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/d347h-eth/aesgcm/internal/domain"
+)
+
+// MAX_ECC_PARITY is the maximum number of Reed-Solomon parity bytes per codeword
+const MAX_ECC_PARITY = 128
+
+// eccHeader is the armor header carrying Reed-Solomon parameters
+const eccHeader = "Error-Correction"
+
+// eccParameterPrefix starts Reed-Solomon parameters, "=" isn't a part of the Base32 alphabet,
+// so the parameters can follow the prefix of Base32 encoding
+const eccParameterPrefix = "RS="
+
+// rsCodewordLength is the maximum length of Reed-Solomon codeword over GF(2^8)
+const rsCodewordLength = 255
+
+// armorLineBytes is the amount of bytes encoded on a single armor body line
+const armorLineBytes = armorLineLength / 4 * 3
+
+// maxECCLength limits the protected data length, so the size with parity, which is at most
+// twice as large, can't overflow int on 32-bit platforms
+const maxECCLength = math.MaxInt32 / 4
+
+// base32BlockBytes is the amount of bytes encoded in a single Base32 block
+const base32BlockBytes = checkedBlockLength * 5 / 8
+
+type (
+	// eccLayout describes how the data is spread over interleaved Reed-Solomon codewords:
+	// byte i of codeword j is stored at position i*codewords+j, so a damaged line
+	// costs every codeword only a few bytes
+	eccLayout struct {
+		parity    int
+		length    int
+		codewords int
+		// dataLength is the amount of data bytes per codeword, the data is padded with zeros
+		dataLength int
+	}
+)
+
+func newECCLayout(parity int, length int) (eccLayout, error) {
+	if parity <= 0 || parity > MAX_ECC_PARITY {
+		return eccLayout{}, fmt.Errorf("invalid Reed-Solomon parity: %d", parity)
+	}
+	if length < 0 || length > maxECCLength {
+		return eccLayout{}, fmt.Errorf("invalid Reed-Solomon data length: %d", length)
+	}
+	codewords := max((length+rsCodewordLength-parity-1)/(rsCodewordLength-parity), 1)
+	return eccLayout{parity, length, codewords, (length + codewords - 1) / codewords}, nil
+}
+
+// parseECCParameters parses "RS=<parity>/<length>", the length is untrusted, so it's limited by the length
+// of the text before anything is allocated: the encoded data is never shorter than the data itself
+func parseECCParameters(parameters string, textLength int) (eccLayout, error) {
+	var parity, length int
+	if _, err := fmt.Sscanf(strings.TrimSpace(parameters), eccParameterPrefix+"%d/%d", &parity, &length); err != nil {
+		return eccLayout{}, fmt.Errorf("malformed Reed-Solomon parameters: %q", parameters)
+	}
+	if length > textLength {
+		return eccLayout{}, fmt.Errorf("Reed-Solomon data length %d exceeds the length of the text", length)
+	}
+	return newECCLayout(parity, length)
+}
+
+func (l eccLayout) String() string {
+	return fmt.Sprintf("%s%d/%d", eccParameterPrefix, l.parity, l.length)
+}
+
+// size is the length of the protected data
+func (l eccLayout) size() int {
+	return l.codewords * (l.dataLength + l.parity)
+}
+
+// protect adds interleaved Reed-Solomon parity to the data
+func (l eccLayout) protect(data []byte) []byte {
+	padded := make([]byte, l.codewords*l.dataLength)
+	copy(padded, data)
+	output := make([]byte, l.size())
+	for j := 0; j < l.codewords; j++ {
+		codeword := rsEncode(padded[j*l.dataLength:(j+1)*l.dataLength], l.parity)
+		for i, b := range codeword {
+			output[i*l.codewords+j] = b
+		}
+	}
+	return output
+}
+
+// recover corrects the protected data in place, the erased bytes are known to be damaged,
+// returned are the data and positions of the corrected bytes in the protected data
+func (l eccLayout) recover(protected []byte, erased []bool) ([]byte, []int, error) {
+	if len(protected) != l.size() {
+		return nil, nil, fmt.Errorf("protected data length mismatch: expected %d bytes, got %d", l.size(), len(protected))
+	}
+	data := make([]byte, 0, l.codewords*l.dataLength)
+	var corrected []int
+	codeword := make([]byte, l.dataLength+l.parity)
+	for j := 0; j < l.codewords; j++ {
+		var erasures []int
+		for i := range codeword {
+			codeword[i] = protected[i*l.codewords+j]
+			if erased[i*l.codewords+j] {
+				erasures = append(erasures, i)
+			}
+		}
+		changed, err := rsDecode(codeword, l.parity, erasures)
+		if err != nil {
+			return nil, nil, fmt.Errorf("the text is too damaged to be repaired: codeword %d: %w", j+1, err)
+		}
+		for _, i := range changed {
+			protected[i*l.codewords+j] = codeword[i]
+			corrected = append(corrected, i*l.codewords+j)
+		}
+		data = append(data, codeword[:l.dataLength]...)
+	}
+	sort.Ints(corrected)
+	return data[:l.length], corrected, nil
+}
+
+// repairText corrects the text protected with Reed-Solomon parity, false is returned
+// if the text isn't protected (neither armor with the parameters header nor Base32 with parameters)
+func repairText(text []byte) ([]byte, []domain.Repair, bool, error) {
+	if block, err := scanArmor(text); err == nil && block.headers[eccHeader] != "" {
+		data, repairs, err := repairArmor(block, len(text))
+		if err != nil {
+			return nil, nil, true, fmt.Errorf("failed to repair armor: %w", err)
+		}
+		return data, repairs, true, nil
+	}
+	scheme := blockEncodings[ENCODING_BASE32]
+	trimmed := strings.TrimLeft(string(text), " \t\r\n")
+	if !strings.HasPrefix(strings.ToUpper(trimmed), scheme.prefix+eccParameterPrefix) {
+		return nil, nil, false, nil
+	}
+	parameters, body, _ := strings.Cut(trimmed[len(scheme.prefix):], "\n")
+	layout, err := parseECCParameters(parameters, len(body))
+	if err != nil {
+		return nil, nil, true, err
+	}
+	data, repairs, err := repairBase32(layout, body)
+	if err != nil {
+		return nil, nil, true, fmt.Errorf("failed to repair Base32: %w", err)
+	}
+	return data, repairs, true, nil
+}
+
+// repairArmor decodes armor body line by line, unreadable lines and lines failing their checksum
+// are erased and restored from the parity
+func repairArmor(block *armorBlock, textLength int) ([]byte, []domain.Repair, error) {
+	layout, err := parseECCParameters(block.headers[eccHeader], textLength)
+	if err != nil {
+		return nil, nil, err
+	}
+	count := (layout.size() + armorLineBytes - 1) / armorLineBytes
+	protected := make([]byte, layout.size())
+	erased := make([]bool, layout.size())
+	for i := range erased {
+		erased[i] = true
+	}
+	index := -1
+	for _, line := range block.lines {
+		// numbered lines are placed by their number, so a skipped line doesn't shift the rest
+		if line.number > 0 {
+			index = line.number - 1
+		} else {
+			index++
+		}
+		// the number is untrusted, a line beyond the protected data is erased before the offset is computed
+		if line.err != nil || index >= count {
+			continue
+		}
+		start := index * armorLineBytes
+		end := min(start+armorLineBytes, layout.size())
+		decoded, err := base64.StdEncoding.DecodeString(line.content)
+		if err != nil || len(decoded) != end-start {
+			continue
+		}
+		copy(protected[start:end], decoded)
+		for i := start; i < end; i++ {
+			erased[i] = false
+		}
+	}
+	data, corrected, err := layout.recover(protected, erased)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := block.verify(protected); err != nil {
+		return nil, nil, err
+	}
+	return data, eccRepairs(corrected, armorLineBytes, 1), nil
+}
+
+// repairBase32 decodes Base32 blocks one by one, blocks with invalid characters or failing their checksum
+// are erased and restored from the parity
+func repairBase32(layout eccLayout, body string) ([]byte, []domain.Repair, error) {
+	scheme := blockEncodings[ENCODING_BASE32]
+	chars, _ := stripText([]byte(strings.ToUpper(body)), 0)
+	protected := make([]byte, layout.size())
+	erased := make([]bool, layout.size())
+	blocks := (layout.size() + base32BlockBytes - 1) / base32BlockBytes
+	blockLength := checkedBlockLength + scheme.checksumLength
+	for i := 0; i < blocks; i++ {
+		start := i * base32BlockBytes
+		end := min(start+base32BlockBytes, layout.size())
+		var decoded []byte
+		if i*blockLength+scheme.checksumLength < len(chars) {
+			block := chars[i*blockLength : min((i+1)*blockLength, len(chars))]
+			data, checksum := block[:len(block)-scheme.checksumLength], block[len(block)-scheme.checksumLength:]
+			if scheme.checksumEqual(scheme.checksum(i, data, i == blocks-1), checksum) {
+				decoded, _ = scheme.decode(data)
+			}
+		}
+		if len(decoded) != end-start {
+			for j := start; j < end; j++ {
+				erased[j] = true
+			}
+			continue
+		}
+		copy(protected[start:end], decoded)
+	}
+	data, corrected, err := layout.recover(protected, erased)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, eccRepairs(corrected, base32BlockBytes, checkedBlocksPerLine), nil
+}
+
+// eccRepairs groups corrected positions by the lines they have been read from
+func eccRepairs(corrected []int, unitBytes int, unitsPerLine int) []domain.Repair {
+	var repairs []domain.Repair
+	for _, position := range corrected {
+		line := position/unitBytes/unitsPerLine + 1
+		if len(repairs) > 0 && repairs[len(repairs)-1].Line == line {
+			repairs[len(repairs)-1].Bytes++
+			continue
+		}
+		repairs = append(repairs, domain.Repair{Line: line, Bytes: 1})
+	}
+	return repairs
+}
@@ -0,0 +1,380 @@
+package container
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+	"strings"
+)
+
+// ENCODING_BASE64 is Base64 text with per-block checksums
+const ENCODING_BASE64 = "base64"
+
+// ENCODING_BASE64URL is URL-safe Base64 text with per-block checksums
+const ENCODING_BASE64URL = "base64url"
+
+// ENCODING_BASE32 is case-insensitive Base32 text with per-block checksums
+const ENCODING_BASE32 = "base32"
+
+// ENCODING_HEX is case-insensitive hexadecimal text with per-block checksums
+const ENCODING_HEX = "hex"
+
+// ENCODING_BASE58CHECK is Base58 text with double SHA-256 checksum
+const ENCODING_BASE58CHECK = "base58check"
+
+// ENCODING_BECH32 is Bech32m text split into segments of at most 90 characters, each with its own BCH checksum
+const ENCODING_BECH32 = "bech32"
+
+// ENCODING_UR is Uniform Resource (ur:bytes) with bytewords, split into fountain-coded parts if it's large
+const ENCODING_UR = "ur"
+
+// ENCODING_WORDS is BIP39 English words with a checksum word after every 7 words
+const ENCODING_WORDS = "words"
+
+// Encodings lists all supported text encodings
+var Encodings = []string{
+	ENCODING_BASE64,
+	ENCODING_BASE64URL,
+	ENCODING_BASE32,
+	ENCODING_BASE58CHECK,
+	ENCODING_HEX,
+	ENCODING_BECH32,
+	ENCODING_UR,
+	ENCODING_WORDS,
+}
+
+// QR_ENCODING_NONE keeps the configured output format in QR codes
+const QR_ENCODING_NONE = "none"
+
+// QR_ENCODING_BASE45 encodes the binary container in QR codes as Base45 (RFC 9285)
+const QR_ENCODING_BASE45 = "base45"
+
+// QR_ENCODING_BASE32 encodes the binary container in QR codes as uppercase Base32 without padding
+const QR_ENCODING_BASE32 = "base32"
+
+// QREncodings lists all supported QR code payload encodings
+var QREncodings = []string{QR_ENCODING_NONE, QR_ENCODING_BASE45, QR_ENCODING_BASE32}
+
+// QR payload encodings use only the characters of QR alphanumeric mode, including their prefixes
+const (
+	base45Prefix   = "AESGCM45:"
+	qrBase32Prefix = "AESGCMQ32:"
+)
+
+// checkedBlockLength is the amount of data characters protected by a single block checksum
+const checkedBlockLength = 32
+
+// checkedBlocksPerLine is the amount of blocks per output line
+const checkedBlocksPerLine = 2
+
+// textLineLength is the line length of the encodings without blocks
+const textLineLength = 64
+
+type (
+	// blockEncoding is a text encoding protected with per-block checksums
+	blockEncoding struct {
+		prefix          string
+		alphabet        string
+		caseInsensitive bool
+		checksumLength  int
+		encode          func(data []byte) string
+		decode          func(text string) ([]byte, error)
+	}
+
+	// TranscriptionError reports a character which doesn't match the checksum,
+	// so it has most likely been mistyped or misread
+	TranscriptionError struct {
+		// Position is a zero-based offset of the character in the input (reported one-based)
+		Position int
+		Line     int
+		Column   int
+		// Suggestion is the character which makes the checksum valid, if it's unambiguous
+		Suggestion string
+		Reason     string
+	}
+)
+
+var blockEncodings = map[string]blockEncoding{
+	ENCODING_BASE64: {
+		prefix:         "AESGCM64:",
+		alphabet:       "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/",
+		checksumLength: 3,
+		encode:         base64.RawStdEncoding.EncodeToString,
+		decode:         base64.RawStdEncoding.DecodeString,
+	},
+	ENCODING_BASE64URL: {
+		prefix:         "AESGCM64U:",
+		alphabet:       "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_",
+		checksumLength: 3,
+		encode:         base64.RawURLEncoding.EncodeToString,
+		decode:         base64.RawURLEncoding.DecodeString,
+	},
+	ENCODING_BASE32: {
+		prefix:          "AESGCM32:",
+		alphabet:        "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567",
+		caseInsensitive: true,
+		checksumLength:  3,
+		encode:          base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString,
+		decode:          base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString,
+	},
+	ENCODING_HEX: {
+		prefix:          "AESGCM16:",
+		alphabet:        "0123456789ABCDEF",
+		caseInsensitive: true,
+		checksumLength:  4,
+		encode: func(data []byte) string {
+			return strings.ToUpper(hex.EncodeToString(data))
+		},
+		decode: hex.DecodeString,
+	},
+}
+
+// Error ...
+func (e *TranscriptionError) Error() string {
+	message := fmt.Sprintf("transcription error at position %d (line %d, column %d)", e.Position+1, e.Line, e.Column)
+	if e.Suggestion != "" {
+		message += fmt.Sprintf(": the character is likely %q", e.Suggestion)
+	}
+	if e.Reason != "" {
+		message += ": " + e.Reason
+	}
+	return message
+}
+
+// encodeText encodes data with the text encoding
+func encodeText(encoding string, data []byte) ([]byte, error) {
+	switch encoding {
+	case ENCODING_BASE58CHECK:
+		return []byte(wrapLines(base58CheckPrefix+encodeBase58Check(data), textLineLength)), nil
+	case ENCODING_BECH32:
+		encoded, err := encodeBech32Segments(data)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(encoded), nil
+	case ENCODING_UR:
+		return encodeUR(data)
+	case ENCODING_WORDS:
+		return []byte(encodeWords(data)), nil
+	}
+	scheme, ok := blockEncodings[encoding]
+	if !ok {
+		return nil, fmt.Errorf("unsupported text encoding: %q", encoding)
+	}
+	return []byte(scheme.encodeBlocks(data, "")), nil
+}
+
+// encodeBlocks encodes data into blocks with checksums, the parameters are written right after the prefix
+func (s blockEncoding) encodeBlocks(data []byte, parameters string) string {
+	encoded := s.encode(data)
+	var output strings.Builder
+	output.WriteString(s.prefix)
+	output.WriteString(parameters)
+	output.WriteByte('\n')
+	for i := 0; i*checkedBlockLength < len(encoded); i++ {
+		end := (i + 1) * checkedBlockLength
+		final := end >= len(encoded)
+		if final {
+			end = len(encoded)
+		}
+		block := encoded[i*checkedBlockLength : end]
+		output.WriteString(block)
+		output.WriteString(s.checksum(i, block, final))
+		if final {
+			output.WriteByte('\n')
+		} else if (i+1)%checkedBlocksPerLine == 0 {
+			output.WriteByte('\n')
+		} else {
+			output.WriteByte(' ')
+		}
+	}
+	return output.String()
+}
+
+// decodeText recognizes the text encoding by its prefix and decodes it,
+// false is returned if the text doesn't look like any of the encodings
+func decodeText(text []byte) ([]byte, bool, error) {
+	upper := strings.ToUpper(string(bytes.TrimSpace(text)))
+	offset := len(text) - len(bytes.TrimLeft(text, " \t\r\n"))
+	// the longest prefix has to be matched first, since base64 prefix is a part of base64url one
+	for _, encoding := range []string{ENCODING_BASE64URL, ENCODING_BASE64, ENCODING_BASE32, ENCODING_HEX} {
+		scheme := blockEncodings[encoding]
+		if strings.HasPrefix(upper, scheme.prefix) {
+			data, err := scheme.decodeBlocks(text, offset+len(scheme.prefix))
+			return data, true, err
+		}
+	}
+	switch {
+	case strings.HasPrefix(upper, base45Prefix):
+		// space is a part of Base45 alphabet, so only line breaks are stripped
+		start := offset + len(base45Prefix)
+		data, err := decodeBase45(text, strings.TrimRight(string(text[start:]), "\r\n"), start)
+		return data, true, err
+	case strings.HasPrefix(upper, qrBase32Prefix):
+		data, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(upper[len(qrBase32Prefix):])
+		if err != nil {
+			return nil, true, fmt.Errorf("failed to decode Base32: %w", err)
+		}
+		return data, true, nil
+	case strings.HasPrefix(upper, base58CheckPrefix):
+		data, err := decodeBase58CheckText(text, offset+len(base58CheckPrefix))
+		return data, true, err
+	case strings.HasPrefix(upper, strings.ToUpper(bech32HRP)+"1"): // bech32 can't contain ":" of the other prefixes
+		data, err := decodeBech32Text(text, offset)
+		return data, true, err
+	case strings.HasPrefix(upper, "UR:"):
+		data, err := decodeURText(text)
+		return data, true, err
+	case isWordsText(text):
+		data, err := decodeWordsText(text)
+		return data, true, err
+	}
+	return nil, false, nil
+}
+
+// checksum is CRC-32 of the block index and its characters truncated to the checksum length,
+// the index binds the block to its place and the final flag detects truncated input
+func (s blockEncoding) checksum(index int, block string, final bool) string {
+	prefix := uint32(index)
+	if final {
+		prefix |= 1 << 31
+	}
+	if s.caseInsensitive {
+		block = strings.ToUpper(block)
+	}
+	sum := crc32.ChecksumIEEE(append(binary.BigEndian.AppendUint32(nil, prefix), block...))
+	bitsPerChar := bitsPerSymbol(len(s.alphabet))
+	checksum := make([]byte, s.checksumLength)
+	for i := range checksum {
+		checksum[i] = s.alphabet[sum&(1<<bitsPerChar-1)]
+		sum >>= bitsPerChar
+	}
+	return string(checksum)
+}
+
+func (s blockEncoding) decodeBlocks(text []byte, start int) ([]byte, error) {
+	chars, positions := stripText(text, start)
+	if len(chars) == 0 {
+		return nil, fmt.Errorf("encoded text is empty")
+	}
+	var encoded strings.Builder
+	blockLength := checkedBlockLength + s.checksumLength
+	for i := 0; i*blockLength < len(chars); i++ {
+		end := (i + 1) * blockLength
+		final := end >= len(chars)
+		if final {
+			end = len(chars)
+		}
+		if end-i*blockLength <= s.checksumLength {
+			return nil, newTranscriptionError(text, positions[len(positions)-1], "", "the text is truncated")
+		}
+		block := chars[i*blockLength : end-s.checksumLength]
+		checksum := chars[end-s.checksumLength : end]
+		for j := 0; j < len(block); j++ {
+			if !s.valid(block[j]) {
+				return nil, newTranscriptionError(text, positions[i*blockLength+j], "",
+					fmt.Sprintf("invalid character %q", block[j]))
+			}
+		}
+		if !s.checksumEqual(s.checksum(i, block, final), checksum) {
+			return nil, s.locate(text, positions[i*blockLength:end], i, block, checksum, final)
+		}
+		encoded.WriteString(block)
+	}
+	if s.caseInsensitive {
+		return s.decode(strings.ToUpper(encoded.String()))
+	}
+	return s.decode(encoded.String())
+}
+
+func (s blockEncoding) valid(char byte) bool {
+	if s.caseInsensitive {
+		return strings.IndexByte(s.alphabet, toUpper(char)) >= 0
+	}
+	return strings.IndexByte(s.alphabet, char) >= 0
+}
+
+func (s blockEncoding) checksumEqual(expected string, actual string) bool {
+	if s.caseInsensitive {
+		return strings.EqualFold(expected, actual)
+	}
+	return expected == actual
+}
+
+// locate tries to find a single character substitution which makes the block valid
+func (s blockEncoding) locate(text []byte, positions []int, index int, block string, checksum string, final bool) error {
+	candidate, suggestion := -1, ""
+	ambiguous := false
+	blockChars := []byte(block + checksum)
+	for i := range blockChars {
+		original := blockChars[i]
+		for j := 0; j < len(s.alphabet); j++ {
+			if s.alphabet[j] == original || (s.caseInsensitive && s.alphabet[j] == toUpper(original)) {
+				continue
+			}
+			blockChars[i] = s.alphabet[j]
+			if s.checksumEqual(s.checksum(index, string(blockChars[:len(block)]), final), string(blockChars[len(block):])) {
+				ambiguous = ambiguous || candidate >= 0
+				candidate, suggestion = i, string(s.alphabet[j])
+			}
+		}
+		blockChars[i] = original
+	}
+	if candidate >= 0 && !ambiguous {
+		return newTranscriptionError(text, positions[candidate], suggestion, "")
+	}
+	return newTranscriptionError(text, positions[0], "",
+		fmt.Sprintf("checksum mismatch in the block of %d characters starting here", len(positions)))
+}
+
+// stripText drops whitespace and returns the remaining characters with their positions in the text
+func stripText(text []byte, start int) (string, []int) {
+	var chars []byte
+	var positions []int
+	for i := start; i < len(text); i++ {
+		switch text[i] {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		chars = append(chars, text[i])
+		positions = append(positions, i)
+	}
+	return string(chars), positions
+}
+
+func newTranscriptionError(text []byte, position int, suggestion string, reason string) *TranscriptionError {
+	line := bytes.Count(text[:position], []byte{'\n'}) + 1
+	column := position - bytes.LastIndexByte(text[:position], '\n')
+	return &TranscriptionError{position, line, column, suggestion, reason}
+}
+
+func wrapLines(text string, length int) string {
+	var output strings.Builder
+	for len(text) > length {
+		output.WriteString(text[:length])
+		output.WriteByte('\n')
+		text = text[length:]
+	}
+	output.WriteString(text)
+	output.WriteByte('\n')
+	return output.String()
+}
+
+func bitsPerSymbol(alphabetLength int) int {
+	bits := 0
+	for 1<<(bits+1) <= alphabetLength {
+		bits++
+	}
+	return bits
+}
+
+func toUpper(char byte) byte {
+	if char >= 'a' && char <= 'z' {
+		return char - 'a' + 'A'
+	}
+	return char
+}
@@ -0,0 +1,258 @@
+package container
+
+import "fmt"
+
+// Reed-Solomon code over GF(2^8) with primitive polynomial x^8+x^4+x^3+x^2+1,
+// the generator polynomial has roots α^0 … α^(parity-1)
+const gfPrimitive = 0x11d
+
+var gfExp, gfLog = func() ([512]byte, [256]int) {
+	var exp [512]byte
+	var log [256]int
+	x := 1
+	for i := 0; i < 255; i++ {
+		exp[i] = byte(x)
+		log[x] = i
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= gfPrimitive
+		}
+	}
+	for i := 255; i < 512; i++ {
+		exp[i] = exp[i-255]
+	}
+	return exp, log
+}()
+
+func gfMul(x byte, y byte) byte {
+	if x == 0 || y == 0 {
+		return 0
+	}
+	return gfExp[gfLog[x]+gfLog[y]]
+}
+
+func gfDiv(x byte, y byte) byte {
+	if x == 0 {
+		return 0
+	}
+	return gfExp[(gfLog[x]+255-gfLog[y])%255]
+}
+
+// gfPow2 returns α^power, the power may be negative
+func gfPow2(power int) byte {
+	return gfExp[((power%255)+255)%255]
+}
+
+func gfInverse(x byte) byte {
+	return gfExp[255-gfLog[x]]
+}
+
+// polynomials are stored with the highest degree coefficient first
+
+func polyScale(p []byte, x byte) []byte {
+	output := make([]byte, len(p))
+	for i := range p {
+		output[i] = gfMul(p[i], x)
+	}
+	return output
+}
+
+func polyAdd(p []byte, q []byte) []byte {
+	output := make([]byte, max(len(p), len(q)))
+	for i := range p {
+		output[i+len(output)-len(p)] = p[i]
+	}
+	for i := range q {
+		output[i+len(output)-len(q)] ^= q[i]
+	}
+	return output
+}
+
+func polyMul(p []byte, q []byte) []byte {
+	output := make([]byte, len(p)+len(q)-1)
+	for j := range q {
+		for i := range p {
+			output[i+j] ^= gfMul(p[i], q[j])
+		}
+	}
+	return output
+}
+
+func polyEval(p []byte, x byte) byte {
+	y := p[0]
+	for i := 1; i < len(p); i++ {
+		y = gfMul(y, x) ^ p[i]
+	}
+	return y
+}
+
+func reverse(p []byte) []byte {
+	output := make([]byte, len(p))
+	for i := range p {
+		output[len(p)-1-i] = p[i]
+	}
+	return output
+}
+
+func rsGenerator(parity int) []byte {
+	generator := []byte{1}
+	for i := 0; i < parity; i++ {
+		generator = polyMul(generator, []byte{1, gfPow2(i)})
+	}
+	return generator
+}
+
+// rsEncode returns the message followed by parity bytes
+func rsEncode(message []byte, parity int) []byte {
+	generator := rsGenerator(parity)
+	output := make([]byte, len(message)+parity)
+	copy(output, message)
+	for i := range message {
+		coef := output[i]
+		if coef == 0 {
+			continue
+		}
+		for j := 1; j < len(generator); j++ {
+			output[i+j] ^= gfMul(generator[j], coef)
+		}
+	}
+	copy(output, message)
+	return output
+}
+
+// rsDecode corrects the codeword in place, up to parity erasures (known positions)
+// or half as many errors can be corrected, returned are positions of the changed bytes
+func rsDecode(codeword []byte, parity int, erasures []int) ([]int, error) {
+	if len(erasures) > parity {
+		return nil, fmt.Errorf("too many erasures: %d", len(erasures))
+	}
+	original := append([]byte(nil), codeword...)
+	for _, position := range erasures {
+		codeword[position] = 0
+	}
+	syndromes := rsSyndromes(codeword, parity)
+	if !allZero(syndromes) {
+		forney := rsForneySyndromes(syndromes, erasures, len(codeword))
+		locator, err := rsErrorLocator(forney, parity, len(erasures))
+		if err != nil {
+			return nil, err
+		}
+		errors, err := rsFindErrors(reverse(locator), len(codeword))
+		if err != nil {
+			return nil, err
+		}
+		rsCorrectErrata(codeword, syndromes, append(append([]int(nil), erasures...), errors...))
+		if !allZero(rsSyndromes(codeword, parity)) {
+			return nil, fmt.Errorf("could not correct the codeword")
+		}
+	}
+	var changed []int
+	for i := range codeword {
+		if codeword[i] != original[i] {
+			changed = append(changed, i)
+		}
+	}
+	return changed, nil
+}
+
+// rsSyndromes returns the syndromes prefixed with zero, which simplifies indexing
+func rsSyndromes(codeword []byte, parity int) []byte {
+	syndromes := make([]byte, parity+1)
+	for i := 0; i < parity; i++ {
+		syndromes[i+1] = polyEval(codeword, gfPow2(i))
+	}
+	return syndromes
+}
+
+// rsForneySyndromes removes the erasures from the syndromes, so only the errors are left to be located
+func rsForneySyndromes(syndromes []byte, erasures []int, length int) []byte {
+	forney := append([]byte(nil), syndromes[1:]...)
+	for _, position := range erasures {
+		x := gfPow2(length - 1 - position)
+		for j := 0; j < len(forney)-1; j++ {
+			forney[j] = gfMul(forney[j], x) ^ forney[j+1]
+		}
+	}
+	return forney
+}
+
+// rsErrorLocator finds the error locator polynomial with Berlekamp-Massey algorithm
+func rsErrorLocator(syndromes []byte, parity int, erasureCount int) ([]byte, error) {
+	locator, old := []byte{1}, []byte{1}
+	shift := len(syndromes) - parity
+	for i := 0; i < parity-erasureCount; i++ {
+		k := i + shift
+		delta := syndromes[k]
+		for j := 1; j < len(locator); j++ {
+			delta ^= gfMul(locator[len(locator)-1-j], syndromes[k-j])
+		}
+		old = append(old, 0)
+		if delta != 0 {
+			if len(old) > len(locator) {
+				scaled := polyScale(old, delta)
+				old = polyScale(locator, gfInverse(delta))
+				locator = scaled
+			}
+			locator = polyAdd(locator, polyScale(old, delta))
+		}
+	}
+	for len(locator) > 0 && locator[0] == 0 {
+		locator = locator[1:]
+	}
+	if errors := len(locator) - 1; errors*2+erasureCount > parity {
+		return nil, fmt.Errorf("too many errors to correct")
+	}
+	return locator, nil
+}
+
+// rsFindErrors finds the roots of the error locator polynomial by brute force (Chien search)
+func rsFindErrors(locator []byte, length int) ([]int, error) {
+	var positions []int
+	for i := 0; i < length; i++ {
+		if polyEval(locator, gfPow2(i)) == 0 {
+			positions = append(positions, length-1-i)
+		}
+	}
+	if len(positions) != len(locator)-1 {
+		return nil, fmt.Errorf("could not locate the errors")
+	}
+	return positions, nil
+}
+
+// rsCorrectErrata computes error magnitudes with Forney algorithm and applies them
+func rsCorrectErrata(codeword []byte, syndromes []byte, positions []int) {
+	coefficients := make([]int, len(positions))
+	locator := []byte{1}
+	for i, position := range positions {
+		coefficients[i] = len(codeword) - 1 - position
+		locator = polyMul(locator, polyAdd([]byte{1}, []byte{gfPow2(coefficients[i]), 0}))
+	}
+	// error evaluator is the remainder of syndromes × locator divided by x^(errata+1)
+	product := polyMul(reverse(syndromes), locator)
+	evaluator := reverse(product[len(product)-len(locator):])
+
+	x := make([]byte, len(coefficients))
+	for i, coefficient := range coefficients {
+		x[i] = gfPow2(-(255 - coefficient))
+	}
+	for i, xi := range x {
+		xiInverse := gfInverse(xi)
+		derivative := byte(1)
+		for j, xj := range x {
+			if j != i {
+				derivative = gfMul(derivative, 1^gfMul(xiInverse, xj))
+			}
+		}
+		y := gfMul(xi, polyEval(reverse(evaluator), xiInverse))
+		codeword[positions[i]] ^= gfDiv(y, derivative)
+	}
+}
+
+func allZero(values []byte) bool {
+	for _, value := range values {
+		if value != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,445 @@
+package container
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"math/bits"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/d347h-eth/aesgcm/internal/domain"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// urPrefix is the scheme and type of Uniform Resources (BCR-2020-005) carrying the container
+const urPrefix = "ur:bytes/"
+
+// URs with longer CBOR message are split into multiple parts with fountain-coded fragments
+const (
+	urMaxFragmentLength = 200
+	urMinFragmentLength = 10
+)
+
+// bytewords is the BCR-2020-012 word list, minimal encoding uses the first and the last letter of each word
+var bytewords = strings.Fields(`
+able acid also apex aqua arch atom aunt away axis back bald barn belt beta bias
+blue body brag brew bulb buzz calm cash cats chef city claw code cola cook cost
+crux curl cusp cyan dark data days deli dice diet door down draw drop drum dull
+duty each easy echo edge epic even exam exit eyes fact fair fern figs film fish
+fizz flap flew flux foxy free frog fuel fund gala game gear gems gift girl glow
+good gray grim guru gush gyro half hang hard hawk heat help high hill holy hope
+horn huts iced idea idle inch inky into iris iron item jade jazz join jolt jowl
+judo jugs jump junk jury keep keno kept keys kick kiln king kite kiwi knob lamb
+lava lazy leaf legs liar limp lion list logo loud love luau luck lung main many
+math maze memo menu meow mild mint miss monk nail navy need news next noon note
+numb obey oboe omit onyx open oval owls paid part peck play plus poem pool pose
+puff puma purr quad quiz race ramp real redo rich road rock roof ruby ruin runs
+rust safe saga scar sets silk skew slot soap solo song stub surf swan taco task
+taxi tent tied time tiny toil tomb toys trip tuna twin ugly undo unit urge user
+vast very veto vial vibe view visa void vows wall wand warm wasp wave waxy webs
+what when whiz wolf work yank yawn yell yoga yurt zaps zero zest zinc zone zoom`)
+
+var minimalBytewords = func() map[string]byte {
+	index := make(map[string]byte, len(bytewords))
+	for i, word := range bytewords {
+		index[word[:1]+word[3:]] = byte(i)
+	}
+	return index
+}()
+
+type (
+	// urPart is a single part of multipart UR
+	urPart struct {
+		_          struct{} `cbor:",toarray"`
+		SeqNum     uint32
+		SeqLen     int
+		MessageLen int
+		Checksum   uint32
+		Data       []byte
+	}
+
+	// urToken is a single UR found in the text with its offset
+	urToken struct {
+		text   string
+		offset int
+	}
+)
+
+// encodeUR encodes data as ur:bytes, one UR per line if it has to be split
+func encodeUR(data []byte) ([]byte, error) {
+	message, err := cbor.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(message) <= urMaxFragmentLength {
+		return []byte(urPrefix + encodeBytewords(message) + "\n"), nil
+	}
+
+	fragmentLength := urFragmentLength(len(message))
+	seqLen := (len(message) + fragmentLength - 1) / fragmentLength
+	checksum := crc32.ChecksumIEEE(message)
+	encoder, err := cbor.CoreDetEncOptions().EncMode()
+	if err != nil {
+		return nil, err
+	}
+	var output strings.Builder
+	for i := 0; i < seqLen; i++ {
+		fragment := make([]byte, fragmentLength)
+		copy(fragment, message[i*fragmentLength:])
+		part, err := encoder.Marshal(urPart{
+			SeqNum:     uint32(i + 1),
+			SeqLen:     seqLen,
+			MessageLen: len(message),
+			Checksum:   checksum,
+			Data:       fragment,
+		})
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&output, "%s%d-%d/%s\n", urPrefix, i+1, seqLen, encodeBytewords(part))
+	}
+	return []byte(output.String()), nil
+}
+
+// decodeURText decodes a single UR or the parts of multipart UR separated with whitespace,
+// the parts may come in any order and include fountain-coded ones produced by other encoders
+func decodeURText(text []byte) ([]byte, error) {
+	var parts []urPart
+	var single []byte
+	for _, token := range urTokens(text) {
+		lower := strings.ToLower(token.text)
+		if !strings.HasPrefix(lower, urPrefix) {
+			return nil, newTranscriptionError(text, token.offset, "", "expected ur:bytes")
+		}
+		path := lower[len(urPrefix):]
+		slash := strings.IndexByte(path, '/')
+		if slash < 0 {
+			message, err := decodeBytewords(text, path, token.offset+len(urPrefix))
+			if err != nil {
+				return nil, err
+			}
+			single = message
+			continue
+		}
+		sequence := path[:slash]
+		dash := strings.IndexByte(sequence, '-')
+		seqNum, errNum := strconv.ParseUint(sequence[:max(dash, 0)], 10, 32)
+		seqLen, errLen := strconv.Atoi(sequence[dash+1:])
+		if dash < 0 || errNum != nil || errLen != nil || seqNum == 0 {
+			return nil, newTranscriptionError(text, token.offset+len(urPrefix), "",
+				fmt.Sprintf("invalid UR sequence %q", sequence))
+		}
+		encoded, err := decodeBytewords(text, path[slash+1:], token.offset+len(urPrefix)+slash+1)
+		if err != nil {
+			return nil, err
+		}
+		var part urPart
+		if err := cbor.Unmarshal(encoded, &part); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal UR part %s: %w", sequence, err)
+		}
+		if uint64(part.SeqNum) != seqNum || part.SeqLen != seqLen {
+			return nil, fmt.Errorf("UR part %s doesn't match its sequence", sequence)
+		}
+		parts = append(parts, part)
+	}
+
+	message := single
+	switch {
+	case single != nil && len(parts) > 0:
+		return nil, fmt.Errorf("single-part UR can't be combined with multipart UR")
+	case single == nil && len(parts) == 0:
+		return nil, fmt.Errorf("no UR has been found")
+	case len(parts) > 0:
+		var err error
+		if message, err = joinURParts(parts); err != nil {
+			return nil, err
+		}
+	}
+	var data []byte
+	if err := cbor.Unmarshal(message, &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal UR message: %w", err)
+	}
+	return data, nil
+}
+
+func urTokens(text []byte) []urToken {
+	var tokens []urToken
+	start := -1
+	for i := 0; i <= len(text); i++ {
+		space := i == len(text) || text[i] == ' ' || text[i] == '\t' || text[i] == '\r' || text[i] == '\n'
+		switch {
+		case space && start >= 0:
+			tokens = append(tokens, urToken{string(text[start:i]), start})
+			start = -1
+		case !space && start < 0:
+			start = i
+		}
+	}
+	return tokens
+}
+
+// joinURParts reassembles the message from the parts, fountain-coded ones are peeled
+func joinURParts(parts []urPart) ([]byte, error) {
+	first := parts[0]
+	if first.SeqLen <= 0 || first.MessageLen <= 0 || len(first.Data) == 0 {
+		return nil, fmt.Errorf("invalid UR part")
+	}
+	// the fragments evenly cover the message, so the sequence length is implied by the lengths
+	if first.SeqLen != (first.MessageLen-1)/len(first.Data)+1 {
+		return nil, fmt.Errorf("invalid UR sequence length: %d", first.SeqLen)
+	}
+	peeler, err := domain.NewPeeler(first.SeqLen)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UR sequence length: %w", err)
+	}
+	for _, part := range parts {
+		if part.SeqLen != first.SeqLen || part.MessageLen != first.MessageLen ||
+			part.Checksum != first.Checksum || len(part.Data) != len(first.Data) {
+			return nil, fmt.Errorf("UR parts belong to different messages")
+		}
+		peeler.Add(urChooseFragments(part.SeqNum, part.SeqLen, part.Checksum), append([]byte(nil), part.Data...))
+	}
+	fragments := peeler.Blocks()
+	if !peeler.Complete() {
+		var missing []string
+		for i, fragment := range fragments {
+			if fragment == nil {
+				missing = append(missing, strconv.Itoa(i+1))
+			}
+		}
+		return nil, fmt.Errorf("missing UR fragments %s of %d", strings.Join(missing, ", "), first.SeqLen)
+	}
+	message := bytes.Join(fragments, nil)
+	if len(message) < first.MessageLen {
+		return nil, fmt.Errorf("UR message is truncated")
+	}
+	message = message[:first.MessageLen]
+	if crc32.ChecksumIEEE(message) != first.Checksum {
+		return nil, fmt.Errorf("UR message checksum mismatch")
+	}
+	return message, nil
+}
+
+func encodeBytewords(data []byte) string {
+	sum := binary.BigEndian.AppendUint32(nil, crc32.ChecksumIEEE(data))
+	var output strings.Builder
+	for _, b := range append(append([]byte(nil), data...), sum...) {
+		word := bytewords[b]
+		output.WriteByte(word[0])
+		output.WriteByte(word[3])
+	}
+	return output.String()
+}
+
+// decodeBytewords decodes minimal bytewords and verifies the CRC-32 suffix,
+// start is the offset of the words in the text for error reporting
+func decodeBytewords(text []byte, words string, start int) ([]byte, error) {
+	if len(words)%2 != 0 {
+		return nil, newTranscriptionError(text, start+len(words)-1, "", "odd number of byteword letters")
+	}
+	data := make([]byte, len(words)/2)
+	invalid := -1
+	for i := range data {
+		b, ok := minimalBytewords[words[2*i:2*i+2]]
+		if !ok && invalid < 0 {
+			invalid = i
+		}
+		data[i] = b
+	}
+	if len(data) < 5 {
+		return nil, newTranscriptionError(text, start, "", "the text is truncated")
+	}
+	if invalid < 0 && bytewordsChecksumValid(data) {
+		return data[:len(data)-4], nil
+	}
+
+	reason, position := "bytewords checksum mismatch", start
+	if invalid >= 0 {
+		reason, position = fmt.Sprintf("invalid byteword %q", words[2*invalid:2*invalid+2]), start+2*invalid
+	}
+	if offset, letter, ok := locateBytewords(words, data, invalid); ok {
+		suggestion := string(letter)
+		if text[start+offset] >= 'A' && text[start+offset] <= 'Z' {
+			suggestion = strings.ToUpper(suggestion)
+		}
+		return nil, newTranscriptionError(text, start+offset, suggestion, reason)
+	}
+	return nil, newTranscriptionError(text, position, "", reason)
+}
+
+// locateBytewords tries to find a single letter substitution which makes the words valid,
+// only the letters of the invalid byteword are considered if there is one
+func locateBytewords(words string, data []byte, invalid int) (int, byte, bool) {
+	from, to := 0, len(words)
+	if invalid >= 0 {
+		from, to = 2*invalid, 2*invalid+2
+	}
+	candidate, letter, found := 0, byte(0), false
+	pair := make([]byte, 2)
+	for offset := from; offset < to; offset++ {
+		index := offset / 2
+		original := data[index]
+		copy(pair, words[2*index:2*index+2])
+		for c := byte('a'); c <= 'z'; c++ {
+			if c == words[offset] {
+				continue
+			}
+			pair[offset%2] = c
+			b, ok := minimalBytewords[string(pair)]
+			if !ok {
+				continue
+			}
+			data[index] = b
+			if bytewordsChecksumValid(data) {
+				if found {
+					data[index] = original
+					return 0, 0, false
+				}
+				candidate, letter, found = offset, c, true
+			}
+		}
+		data[index] = original
+	}
+	return candidate, letter, found
+}
+
+func bytewordsChecksumValid(data []byte) bool {
+	payload, sum := data[:len(data)-4], data[len(data)-4:]
+	return crc32.ChecksumIEEE(payload) == binary.BigEndian.Uint32(sum)
+}
+
+// urFragmentLength finds the smallest number of fragments not exceeding the maximal length
+func urFragmentLength(messageLength int) int {
+	fragmentLength := messageLength
+	for count := 1; count <= messageLength/urMinFragmentLength; count++ {
+		fragmentLength = (messageLength + count - 1) / count
+		if fragmentLength <= urMaxFragmentLength {
+			break
+		}
+	}
+	return fragmentLength
+}
+
+// urChooseFragments returns indexes of the fragments mixed into the part,
+// it follows the reference implementation so parts of other encoders can be decoded
+func urChooseFragments(seqNum uint32, seqLen int, checksum uint32) []int {
+	if int(seqNum) <= seqLen {
+		return []int{int(seqNum) - 1}
+	}
+	seed := sha256.Sum256(binary.BigEndian.AppendUint32(binary.BigEndian.AppendUint32(nil, seqNum), checksum))
+	rng := newXoshiro256(seed)
+	probabilities := make([]float64, seqLen)
+	for i := range probabilities {
+		probabilities[i] = 1 / float64(i+1)
+	}
+	degree := newRandomSampler(probabilities).next(rng) + 1
+	remaining := make([]int, seqLen)
+	for i := range remaining {
+		remaining[i] = i
+	}
+	shuffled := make([]int, 0, seqLen)
+	for len(remaining) > 0 {
+		index := rng.nextInt(0, len(remaining)-1)
+		shuffled = append(shuffled, remaining[index])
+		remaining = append(remaining[:index], remaining[index+1:]...)
+	}
+	chosen := shuffled[:degree]
+	sort.Ints(chosen)
+	return chosen
+}
+
+// xoshiro256 is xoshiro256** generator seeded the same way as in the UR reference implementation
+type xoshiro256 [4]uint64
+
+func newXoshiro256(seed [32]byte) *xoshiro256 {
+	var s xoshiro256
+	for i := range s {
+		s[i] = binary.BigEndian.Uint64(seed[i*8:])
+	}
+	return &s
+}
+
+func (s *xoshiro256) next() uint64 {
+	result := bits.RotateLeft64(s[1]*5, 7) * 9
+	t := s[1] << 17
+	s[2] ^= s[0]
+	s[3] ^= s[1]
+	s[1] ^= s[2]
+	s[0] ^= s[3]
+	s[2] ^= t
+	s[3] = bits.RotateLeft64(s[3], 45)
+	return result
+}
+
+func (s *xoshiro256) nextDouble() float64 {
+	return float64(s.next()) / (float64(math.MaxUint64) + 1)
+}
+
+func (s *xoshiro256) nextInt(low int, high int) int {
+	return int(s.nextDouble()*float64(high-low+1)) + low
+}
+
+// randomSampler is Vose's alias method in the order used by the UR reference implementation
+type randomSampler struct {
+	probabilities []float64
+	aliases       []int
+}
+
+func newRandomSampler(weights []float64) *randomSampler {
+	n := len(weights)
+	sum := 0.0
+	for _, weight := range weights {
+		sum += weight
+	}
+	scaled := make([]float64, n)
+	for i, weight := range weights {
+		scaled[i] = weight * float64(n) / sum
+	}
+	var small, large []int
+	for i := n - 1; i >= 0; i-- {
+		if scaled[i] < 1 {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+	}
+	sampler := &randomSampler{make([]float64, n), make([]int, n)}
+	for len(small) > 0 && len(large) > 0 {
+		a, g := small[len(small)-1], large[len(large)-1]
+		small, large = small[:len(small)-1], large[:len(large)-1]
+		sampler.probabilities[a] = scaled[a]
+		sampler.aliases[a] = g
+		scaled[g] += scaled[a] - 1
+		if scaled[g] < 1 {
+			small = append(small, g)
+		} else {
+			large = append(large, g)
+		}
+	}
+	for _, i := range append(small, large...) {
+		sampler.probabilities[i] = 1
+	}
+	return sampler
+}
+
+func (r *randomSampler) next(rng *xoshiro256) int {
+	r1, r2 := rng.nextDouble(), rng.nextDouble()
+	i := int(float64(len(r.probabilities)) * r1)
+	if r2 < r.probabilities[i] {
+		return i
+	}
+	return r.aliases[i]
+}
+
+func max(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
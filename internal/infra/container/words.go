@@ -0,0 +1,149 @@
+package container
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"strings"
+
+	"github.com/d347h-eth/aesgcm/internal/infra/wordlist"
+)
+
+// WORDS_PER_CHECKSUM is the number of data words followed by a checksum word,
+// a group of 8 words is printed per line, so 3 lines fill a 24-word steel plate
+const WORDS_PER_CHECKSUM = 7
+
+// wordBits is the amount of bits encoded by a single word of 2048-word list
+const wordBits = 11
+
+// wordsPaddingMarker terminates the data, so the zero bits padding the last word can be told apart from it
+const wordsPaddingMarker = 0x80
+
+// encodeWords maps the data onto BIP39 words, every group of WORDS_PER_CHECKSUM words
+// is followed by a checksum word
+func encodeWords(data []byte) string {
+	values := bitsToValues(append(append([]byte(nil), data...), wordsPaddingMarker))
+	var output strings.Builder
+	for i := 0; i*WORDS_PER_CHECKSUM < len(values); i++ {
+		end := min((i+1)*WORDS_PER_CHECKSUM, len(values))
+		group := append(append([]int(nil), values[i*WORDS_PER_CHECKSUM:end]...),
+			wordsChecksum(i, values[i*WORDS_PER_CHECKSUM:end], end == len(values)))
+		for j, value := range group {
+			if j > 0 {
+				output.WriteByte(' ')
+			}
+			output.WriteString(wordlist.BIP39.Word(value))
+		}
+		output.WriteByte('\n')
+	}
+	return output.String()
+}
+
+// isWordsText reports whether the text starts with a word of the list followed by other words
+func isWordsText(text []byte) bool {
+	fields := bytes.Fields(text)
+	if len(fields) < 2 {
+		return false
+	}
+	_, err := wordlist.BIP39.Resolve(string(fields[0]))
+	return err == nil
+}
+
+// decodeWordsText decodes the words (or their unique prefixes) and verifies checksums of the groups
+func decodeWordsText(text []byte) ([]byte, error) {
+	var values []int
+	var positions []int
+	for i := 0; i < len(text); {
+		if text[i] == ' ' || text[i] == '\t' || text[i] == '\r' || text[i] == '\n' {
+			i++
+			continue
+		}
+		start := i
+		for i < len(text) && !(text[i] == ' ' || text[i] == '\t' || text[i] == '\r' || text[i] == '\n') {
+			i++
+		}
+		word, err := wordlist.BIP39.Resolve(string(text[start:i]))
+		if err != nil {
+			suggestion := ""
+			var unknown *wordlist.UnknownWordError
+			if errors.As(err, &unknown) && len(unknown.Suggestions) == 1 {
+				suggestion = unknown.Suggestions[0]
+			}
+			return nil, newTranscriptionError(text, start, suggestion, err.Error())
+		}
+		value, _ := wordlist.BIP39.Value(word)
+		values = append(values, value)
+		positions = append(positions, start)
+	}
+	groupLength := WORDS_PER_CHECKSUM + 1
+	var data []int
+	for i := 0; i*groupLength < len(values); i++ {
+		end := min((i+1)*groupLength, len(values))
+		if end-i*groupLength < 2 {
+			return nil, newTranscriptionError(text, positions[len(positions)-1], "", "the words are truncated")
+		}
+		group := values[i*groupLength : end-1]
+		if wordsChecksum(i, group, end == len(values)) != values[end-1] {
+			return nil, newTranscriptionError(text, positions[i*groupLength], "",
+				fmt.Sprintf("checksum mismatch in the group of %d words starting here", end-i*groupLength))
+		}
+		data = append(data, group...)
+	}
+	decoded := valuesToBits(data)
+	decoded = bytes.TrimRight(decoded, "\x00")
+	if len(decoded) == 0 || decoded[len(decoded)-1] != wordsPaddingMarker {
+		return nil, fmt.Errorf("invalid padding of the words")
+	}
+	return decoded[:len(decoded)-1], nil
+}
+
+// wordsChecksum is 11 bits of CRC-32 of the group index and its words,
+// the final flag detects truncated input
+func wordsChecksum(index int, values []int, final bool) int {
+	prefix := uint32(index)
+	if final {
+		prefix |= 1 << 31
+	}
+	input := binary.BigEndian.AppendUint32(nil, prefix)
+	for _, value := range values {
+		input = binary.BigEndian.AppendUint16(input, uint16(value))
+	}
+	return int(crc32.ChecksumIEEE(input) & (1<<wordBits - 1))
+}
+
+// bitsToValues splits the data into 11-bit values, the last value is padded with zero bits
+func bitsToValues(data []byte) []int {
+	values := make([]int, 0, (len(data)*8+wordBits-1)/wordBits)
+	accumulator, bits := 0, 0
+	for _, b := range data {
+		accumulator = accumulator<<8 | int(b)
+		bits += 8
+		for bits >= wordBits {
+			bits -= wordBits
+			values = append(values, accumulator>>bits&(1<<wordBits-1))
+		}
+		accumulator &= 1<<bits - 1
+	}
+	if bits > 0 {
+		values = append(values, accumulator<<(wordBits-bits)&(1<<wordBits-1))
+	}
+	return values
+}
+
+// valuesToBits joins 11-bit values into bytes, the incomplete last byte is dropped
+func valuesToBits(values []int) []byte {
+	data := make([]byte, 0, len(values)*wordBits/8)
+	accumulator, bits := 0, 0
+	for _, value := range values {
+		accumulator = accumulator<<wordBits | value
+		bits += wordBits
+		for bits >= 8 {
+			bits -= 8
+			data = append(data, byte(accumulator>>bits))
+		}
+		accumulator &= 1<<bits - 1
+	}
+	return data
+}
@@ -0,0 +1,116 @@
+package editor
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// defaultName is the name of the edited file when the original name is unknown
+const defaultName = "plaintext.txt"
+
+type (
+	// Editor is a component responsible for editing plaintext with an external editor,
+	// the plaintext is written into a private directory on tmpfs and overwritten before removal
+	Editor struct {
+		cfg Config
+	}
+
+	// Config ...
+	Config struct {
+		// Command is the editor command with arguments, e.g. "code --wait"
+		Command string
+		// TmpDir is the RAM-backed directory the plaintext is written to
+		TmpDir string
+	}
+)
+
+// NewEditor ...
+func NewEditor(cfg Config) *Editor {
+	return &Editor{cfg}
+}
+
+// Edit launches the editor on a temporary copy of the plaintext and returns the edited copy,
+// the editor exiting with non-zero code is an error
+func (e Editor) Edit(plaintext []byte, name string) (edited []byte, err error) {
+	args := strings.Fields(e.cfg.Command)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("no editor configured: set $EDITOR")
+	}
+	if info, err := os.Stat(e.cfg.TmpDir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("RAM-backed directory %q isn't available: specify one with --tmpdir", e.cfg.TmpDir)
+	}
+	// the directory keeps swap and backup files of the editor private as well
+	dir, err := os.MkdirTemp(e.cfg.TmpDir, "aesgcm-edit-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer func() {
+		if rmErr := removeSecurely(dir); rmErr != nil && err == nil {
+			err = fmt.Errorf("failed to remove temporary directory %q: %w", dir, rmErr)
+		}
+	}()
+	// the name comes from the encrypted file, so it mustn't point outside of the directory
+	if name = filepath.Base(name); name == "." || name == ".." || name == string(filepath.Separator) {
+		name = defaultName
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, plaintext, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	// the editor shares the terminal and handles Ctrl-C itself, the signals are caught,
+	// so the temporary directory is removed in any case
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(signals)
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("the editor has failed: %w", err)
+	}
+
+	edited, err = os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the edited file: %w", err)
+	}
+	return edited, nil
+}
+
+// removeSecurely overwrites every file in the directory with zeros before removing the directory
+func removeSecurely(dir string) error {
+	var overwriteErr error
+	filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && entry.Type().IsRegular() {
+			if err := overwrite(path); err != nil && overwriteErr == nil {
+				overwriteErr = err
+			}
+		}
+		return nil
+	})
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	return overwriteErr
+}
+
+func overwrite(path string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(make([]byte, info.Size())); err != nil {
+		return err
+	}
+	return file.Sync()
+}
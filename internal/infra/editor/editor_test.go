@@ -0,0 +1,36 @@
+package editor
+
+import (
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestEdit(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test relies on POSIX shell utilities")
+	}
+	tmpDir := t.TempDir()
+	edited, err := NewEditor(Config{Command: "sed -i s/old/new/", TmpDir: tmpDir}).Edit([]byte("old secret\n"), "secret.txt")
+	if err != nil {
+		t.Fatalf("failed to edit: %s", err)
+	}
+	if string(edited) != "new secret\n" {
+		t.Fatalf("unexpected edited plaintext: %q", edited)
+	}
+	if _, err := NewEditor(Config{Command: "false", TmpDir: tmpDir}).Edit([]byte("old secret\n"), "secret.txt"); err == nil {
+		t.Fatalf("expected the failed editor to be reported")
+	}
+	// stored names can't escape the private directory
+	for _, name := range []string{"..", "../../secret.txt", "/", ""} {
+		edited, err := NewEditor(Config{Command: "sed -i s/old/new/", TmpDir: tmpDir}).Edit([]byte("old secret\n"), name)
+		if err != nil || string(edited) != "new secret\n" {
+			t.Fatalf("failed to edit the file named %q: %q, %v", name, edited, err)
+		}
+	}
+	// nothing may be left behind in the temporary directory
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("temporary files have been left: %v, %v", entries, err)
+	}
+}
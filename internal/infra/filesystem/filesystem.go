@@ -1,13 +1,32 @@
 package filesystem
 
-import "os"
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/d347h-eth/aesgcm/internal/domain"
+)
 
 type (
-	FileSystem struct{}
+	FileSystem struct {
+		cfg Config
+	}
+
+	Config struct {
+		// Mode is a permission mode applied to every written file
+		Mode os.FileMode
+		// OverwriteEnabled allows replacing of already existing files
+		OverwriteEnabled bool
+		// XattrsEnabled enables reading and restoring of extended file attributes
+		XattrsEnabled bool
+	}
 )
 
-func NewFileSystem() *FileSystem {
-	return &FileSystem{}
+func NewFileSystem(cfg Config) *FileSystem {
+	return &FileSystem{cfg}
 }
 
 // Read reads file from FS
@@ -15,9 +34,78 @@ func (fs FileSystem) Read(filename string) ([]byte, error) {
 	return os.ReadFile(filename)
 }
 
-// Write writes file into FS
-func (fs FileSystem) Write(filename string, data []byte) error {
-	return os.WriteFile(filename, data, 0644)
+// Write writes file into FS atomically: the data is written into a temporary file
+// in the same directory, synced to disk and only then moved into place,
+// so the destination never contains partially written data
+func (fs FileSystem) Write(filename string, data []byte) (err error) {
+	dir := filepath.Dir(filename)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpName := tmp.Name()
+	defer func() {
+		// the temporary file is either renamed, linked or has to be cleaned up
+		if rmErr := os.Remove(tmpName); rmErr != nil && !os.IsNotExist(rmErr) && err == nil {
+			err = fmt.Errorf("failed to remove temporary file: %w", rmErr)
+		}
+	}()
+	if err = writeAndSync(tmp, data, fs.cfg.Mode); err != nil {
+		return err
+	}
+	if fs.cfg.OverwriteEnabled {
+		err = os.Rename(tmpName, filename)
+	} else {
+		err = linkExclusive(tmpName, filename)
+	}
+	if err != nil {
+		return err
+	}
+	syncDir(dir)
+	return nil
+}
+
+// ReadMetadata collects metadata of the file
+func (fs FileSystem) ReadMetadata(filename string) (*domain.Metadata, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+	metadata := &domain.Metadata{
+		Name:    filepath.Base(filename),
+		Mode:    uint32(info.Mode().Perm()),
+		ModTime: info.ModTime().UTC(),
+	}
+	metadata.UID, metadata.GID = owner(info)
+	if fs.cfg.XattrsEnabled {
+		metadata.Xattrs, err = readXattrs(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read extended attributes: %w", err)
+		}
+	}
+	return metadata, nil
+}
+
+// WriteMetadata restores metadata of the file, the owner is restored only when running as root;
+// the restored mode is limited by the configured one, so the stored mode never loosens the permissions
+func (fs FileSystem) WriteMetadata(filename string, metadata *domain.Metadata) error {
+	if fs.cfg.XattrsEnabled && len(metadata.Xattrs) > 0 {
+		if err := writeXattrs(filename, metadata.Xattrs); err != nil {
+			return fmt.Errorf("failed to restore extended attributes: %w", err)
+		}
+	}
+	if err := chown(filename, metadata.UID, metadata.GID); err != nil {
+		return fmt.Errorf("failed to restore owner: %w", err)
+	}
+	if err := os.Chmod(filename, os.FileMode(metadata.Mode).Perm()&fs.cfg.Mode.Perm()); err != nil {
+		return fmt.Errorf("failed to restore mode: %w", err)
+	}
+	if !metadata.ModTime.IsZero() {
+		if err := os.Chtimes(filename, metadata.ModTime, metadata.ModTime); err != nil {
+			return fmt.Errorf("failed to restore modification time: %w", err)
+		}
+	}
+	return nil
 }
 
 // ResourceExist checks if file exists in FS
@@ -25,3 +113,66 @@ func (fs FileSystem) ResourceExist(filename string) bool {
 	_, err := os.Stat(filename)
 	return !os.IsNotExist(err)
 }
+
+// ListFiles returns paths of the regular files in the directory sorted by name,
+// if the path is a file itself it's returned as the only entry
+func (fs FileSystem) ListFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+func writeAndSync(f *os.File, data []byte, mode os.FileMode) error {
+	if err := f.Chmod(mode); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to set file mode: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to sync temporary file: %w", err)
+	}
+	return f.Close()
+}
+
+// linkExclusive moves the file into place only if the destination doesn't exist yet,
+// hard links aren't supported everywhere (e.g. FAT), so an exclusive rename is tried then;
+// the destination is never created before the complete file is moved into it
+func linkExclusive(tmpName string, filename string) error {
+	err := os.Link(tmpName, filename)
+	if err != nil && !errors.Is(err, fs.ErrExist) {
+		err = renameExclusive(tmpName, filename)
+	}
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("file already exists at %q: %w", filename, fs.ErrExist)
+	}
+	return err
+}
+
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	defer d.Close()
+	// best effort: not every platform supports syncing directories
+	_ = d.Sync()
+}
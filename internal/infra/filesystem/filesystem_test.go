@@ -0,0 +1,102 @@
+package filesystem
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/d347h-eth/aesgcm/internal/domain"
+)
+
+func TestWriteExclusive(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "output.aes")
+	storage := NewFileSystem(Config{Mode: 0600})
+	if err := storage.Write(path, []byte("first")); err != nil {
+		t.Fatalf("failed to write: %s", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat: %s", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Fatalf("unexpected file mode: got %o, want %o", info.Mode().Perm(), 0600)
+	}
+	err = storage.Write(path, []byte("second"))
+	if !errors.Is(err, fs.ErrExist) {
+		t.Fatalf("expected an error for existing file, got: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if !bytes.Equal(data, []byte("first")) {
+		t.Fatalf("existing file was modified: got %q", data)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("temporary files were left behind: %d entries", len(entries))
+	}
+
+	storage = NewFileSystem(Config{Mode: 0640, OverwriteEnabled: true})
+	if err := storage.Write(path, []byte("second")); err != nil {
+		t.Fatalf("failed to overwrite: %s", err)
+	}
+	data, _ = os.ReadFile(path)
+	if !bytes.Equal(data, []byte("second")) {
+		t.Fatalf("file wasn't overwritten: got %q", data)
+	}
+}
+
+func TestRenameExclusive(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "windows" {
+		t.Skip("exclusive rename isn't supported on this platform")
+	}
+	dir := t.TempDir()
+	tmpName, path := filepath.Join(dir, ".output.tmp"), filepath.Join(dir, "output.aes")
+	for _, name := range []string{tmpName, path} {
+		if err := os.WriteFile(name, []byte(name), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the fallback of filesystems without hard links never replaces the destination
+	if err := renameExclusive(tmpName, path); !errors.Is(err, fs.ErrExist) {
+		t.Fatalf("expected an error for existing file, got: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != path {
+		t.Fatalf("existing file was modified: got %q", data)
+	}
+	os.Remove(path)
+	if err := renameExclusive(tmpName, path); err != nil {
+		t.Fatalf("failed to rename: %s", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != tmpName {
+		t.Fatalf("unexpected content of renamed file: %q", data)
+	}
+}
+
+func TestWriteMetadataLimitsMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret.txt")
+	for _, tc := range []struct {
+		configured os.FileMode
+		stored     uint32
+		want       os.FileMode
+	}{
+		{0600, 0644, 0600},
+		{0600, 0400, 0400},
+		{0750, 0755, 0750},
+	} {
+		storage := NewFileSystem(Config{Mode: tc.configured, OverwriteEnabled: true})
+		if err := storage.Write(path, []byte("secret")); err != nil {
+			t.Fatalf("failed to write: %s", err)
+		}
+		if err := storage.WriteMetadata(path, &domain.Metadata{Mode: tc.stored}); err != nil {
+			t.Fatalf("failed to restore metadata: %s", err)
+		}
+		info, _ := os.Stat(path)
+		if info.Mode().Perm() != tc.want {
+			t.Fatalf("unexpected mode of %o limited by %o: got %o, want %o", tc.stored, tc.configured, info.Mode().Perm(), tc.want)
+		}
+	}
+}
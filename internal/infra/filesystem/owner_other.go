@@ -0,0 +1,13 @@
+//go:build !unix
+
+package filesystem
+
+import "os"
+
+func owner(info os.FileInfo) (*int, *int) {
+	return nil, nil
+}
+
+func chown(filename string, uid *int, gid *int) error {
+	return nil
+}
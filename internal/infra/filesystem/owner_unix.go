@@ -0,0 +1,25 @@
+//go:build unix
+
+package filesystem
+
+import (
+	"os"
+	"syscall"
+)
+
+func owner(info os.FileInfo) (*int, *int) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil, nil
+	}
+	uid, gid := int(stat.Uid), int(stat.Gid)
+	return &uid, &gid
+}
+
+func chown(filename string, uid *int, gid *int) error {
+	// only the superuser is able to give files away
+	if os.Geteuid() != 0 || uid == nil || gid == nil {
+		return nil
+	}
+	return os.Lchown(filename, *uid, *gid)
+}
@@ -0,0 +1,9 @@
+//go:build !(linux || windows)
+
+package filesystem
+
+import "errors"
+
+func renameExclusive(oldName string, newName string) error {
+	return errors.New("exclusive rename is not supported on this platform: use --force to overwrite")
+}
@@ -0,0 +1,23 @@
+package filesystem
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+func renameExclusive(oldName string, newName string) error {
+	from, err := windows.UTF16PtrFromString(oldName)
+	if err != nil {
+		return err
+	}
+	to, err := windows.UTF16PtrFromString(newName)
+	if err != nil {
+		return err
+	}
+	// unlike os.Rename, MoveFileEx fails if the destination exists without MOVEFILE_REPLACE_EXISTING
+	if err := windows.MoveFileEx(from, to, windows.MOVEFILE_WRITE_THROUGH); err != nil {
+		return &os.LinkError{Op: "rename", Old: oldName, New: newName, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build !(linux || darwin)
+
+package filesystem
+
+import "errors"
+
+func readXattrs(filename string) (map[string][]byte, error) {
+	return nil, nil
+}
+
+func writeXattrs(filename string, xattrs map[string][]byte) error {
+	return errors.New("extended attributes are not supported on this platform")
+}
@@ -0,0 +1,54 @@
+//go:build linux || darwin
+
+package filesystem
+
+import (
+	"bytes"
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+func readXattrs(filename string) (map[string][]byte, error) {
+	size, err := unix.Listxattr(filename, nil)
+	if err != nil {
+		if errors.Is(err, unix.ENOTSUP) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if size == 0 {
+		return nil, nil
+	}
+	list := make([]byte, size)
+	size, err = unix.Listxattr(filename, list)
+	if err != nil {
+		return nil, err
+	}
+	xattrs := make(map[string][]byte)
+	for _, name := range bytes.Split(list[:size], []byte{0}) {
+		if len(name) == 0 {
+			continue
+		}
+		valueSize, err := unix.Getxattr(filename, string(name), nil)
+		if err != nil {
+			return nil, err
+		}
+		value := make([]byte, valueSize)
+		valueSize, err = unix.Getxattr(filename, string(name), value)
+		if err != nil {
+			return nil, err
+		}
+		xattrs[string(name)] = value[:valueSize]
+	}
+	return xattrs, nil
+}
+
+func writeXattrs(filename string, xattrs map[string][]byte) error {
+	for name, value := range xattrs {
+		if err := unix.Setxattr(filename, name, value, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package paper
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/d347h-eth/aesgcm/internal/domain"
+	"github.com/d347h-eth/aesgcm/internal/infra/pdf"
+	"github.com/d347h-eth/aesgcm/internal/infra/qrencoder"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// PAGE_A4 is ISO A4 page size
+const PAGE_A4 = "a4"
+
+// PAGE_LETTER is US Letter page size
+const PAGE_LETTER = "letter"
+
+// PageSizes maps page size names to their dimensions in points
+var PageSizes = map[string][2]float64{
+	PAGE_A4:     {595.28, 841.89},
+	PAGE_LETTER: {612, 792},
+}
+
+const (
+	margin       = 40.0
+	qrSize       = 200.0
+	qrSpace      = 20.0
+	textSize     = 8.0
+	lineSpacing  = 11.0
+	headingSize  = 16.0
+	regularSize  = 9.0
+	regularSpace = 13.0
+	// valueLineLength is the amount of characters fitting between the parameter names and the QR code
+	valueLineLength = 45
+)
+
+var recoverySteps = []string{
+	"1. Get the aesgcm tool: https://github.com/d347h-eth/aesgcm (build it with \"make build\").",
+	"2. Scan the QR code and save the scanned text into a file, e.g. backup.aes.",
+	"   Alternatively type the text below into a file exactly as printed, including the BEGIN/END lines",
+	"   and the line numbers with checksums: every line is verified on its own, so a typo is reported",
+	"   with its line number.",
+	"3. Run: aesgcm decrypt backup.aes -o secret.txt and enter the password.",
+	"4. The parameters printed above are stored inside the text, they are listed for reference only.",
+}
+
+// splitRecoverySteps replace the scanning steps when the payload is split across several QR codes
+var splitRecoverySteps = []string{
+	"1. Get the aesgcm tool: https://github.com/d347h-eth/aesgcm (build it with \"make build\").",
+	"2. Take photos of all %d QR codes, several codes may share a photo, and run:",
+	"   aesgcm decrypt photo1.jpg photo2.jpg ... -o secret.txt and enter the password.",
+	"   The codes are reassembled in any order and the missing ones are reported by number.",
+	"   Alternatively type the text below into a file exactly as printed, including the BEGIN/END lines",
+	"   and the line numbers with checksums, and run: aesgcm decrypt backup.aes -o secret.txt",
+	"3. The parameters printed above are stored inside the text, they are listed for reference only.",
+}
+
+type (
+	// Renderer is a component responsible for rendering printable paper backup sheets into PDF
+	Renderer struct {
+		cfg Config
+	}
+
+	// Config ...
+	Config struct {
+		// PageSize is a page size name (a4, letter)
+		PageSize string
+		// QRLevel is error recovery level of the QR code
+		QRLevel int
+	}
+)
+
+// NewRenderer ...
+func NewRenderer(cfg Config) *Renderer {
+	return &Renderer{cfg}
+}
+
+// Render lays out the paper sheet into a PDF document
+func (r *Renderer) Render(sheet *domain.PaperSheet) ([]byte, error) {
+	size, ok := PageSizes[r.cfg.PageSize]
+	if !ok {
+		return nil, fmt.Errorf("unsupported page size: %q", r.cfg.PageSize)
+	}
+	width, height := size[0], size[1]
+	document := pdf.NewDocument(width, height)
+	page := document.AddPage()
+
+	// heading and parameters on the left, QR code on the right
+	y := margin + headingSize
+	page.Text(margin, y, pdf.FONT_HELVETICA_BOLD, headingSize, "AES-GCM Encrypted Paper Backup")
+	y += regularSpace * 2
+	parameters := [][2]string{
+		{"Label", sheet.Label},
+		{"Created", sheet.Created.Format("2006-01-02 15:04:05 MST")},
+		{"Cipher", fmt.Sprintf("AES-%d-GCM, %d-byte nonce", sheet.KeyDerivation.Length*8, sheet.NonceLength)},
+		{"Key derivation", "PBKDF2-HMAC-SHA512"},
+		{"Iterations", fmt.Sprint(sheet.KeyDerivation.Iterations)},
+		{"Salt length", fmt.Sprintf("%d bytes", len(sheet.KeyDerivation.Salt))},
+	}
+	for _, parameter := range parameters {
+		if parameter[1] == "" {
+			continue
+		}
+		page.Text(margin, y, pdf.FONT_HELVETICA_BOLD, regularSize, parameter[0]+":")
+		for _, line := range wrap(parameter[1], valueLineLength) {
+			page.Text(margin+80, y, pdf.FONT_HELVETICA, regularSize, line)
+			y += regularSpace
+		}
+	}
+	var codes []*qrcode.QRCode
+	if len(sheet.QRData) > 0 {
+		var err error
+		codes, err = qrencoder.Codes(sheet.QRData, r.cfg.QRLevel)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode QR code: %w", err)
+		}
+		// the first code is placed next to the parameters, which may be longer than the code
+		drawQR(page, codes[0], width-margin-qrSize, margin, qrSize)
+		bottom := margin + qrSize
+		if len(codes) > 1 {
+			bottom += regularSpace
+			page.Text(width-margin-qrSize, bottom, pdf.FONT_HELVETICA, regularSize, qrCaption(1, len(codes)))
+		}
+		y = math.Max(y, bottom+regularSpace)
+	}
+
+	// recovery instructions
+	steps := recoverySteps
+	if len(codes) > 1 {
+		steps = make([]string, len(splitRecoverySteps))
+		copy(steps, splitRecoverySteps)
+		steps[1] = fmt.Sprintf(steps[1], len(codes))
+	}
+	y += regularSpace
+	page.Text(margin, y, pdf.FONT_HELVETICA_BOLD, regularSize+1, "Recovery")
+	y += regularSpace
+	for _, step := range steps {
+		page.Text(margin, y, pdf.FONT_HELVETICA, regularSize, step)
+		y += regularSpace
+	}
+
+	// the rest of the codes in a grid, continued on the next pages if needed
+	columns := int((width - 2*margin + qrSpace) / (qrSize + qrSpace))
+	for i := 1; i < len(codes); i++ {
+		column := (i - 1) % columns
+		if column == 0 {
+			y += regularSpace
+			if y+qrSize+regularSpace > height-margin {
+				page = document.AddPage()
+				y = margin
+			}
+		}
+		x := margin + float64(column)*(qrSize+qrSpace)
+		drawQR(page, codes[i], x, y, qrSize)
+		page.Text(x, y+qrSize+regularSpace, pdf.FONT_HELVETICA, regularSize, qrCaption(i+1, len(codes)))
+		if column == columns-1 || i == len(codes)-1 {
+			y += qrSize + regularSpace
+		}
+	}
+
+	// armored text, continued on the next pages if needed
+	y += regularSpace
+	page.Line(margin, y-regularSpace/2, width-margin, y-regularSpace/2)
+	y += regularSpace / 2
+	for _, line := range sheet.Lines {
+		if y > height-margin {
+			page = document.AddPage()
+			y = margin + textSize
+		}
+		page.Text(margin, y, pdf.FONT_COURIER, textSize, line)
+		y += lineSpacing
+	}
+	return document.Bytes(), nil
+}
+
+// drawQR draws QR code with vector rectangles, so it stays sharp at any print resolution
+func drawQR(page *pdf.Page, code *qrcode.QRCode, x float64, y float64, size float64) {
+	bitmap := code.Bitmap()
+	module := size / float64(len(bitmap))
+	qrencoder.Runs(bitmap, func(row int, col int, length int) {
+		page.Rect(x+float64(col)*module, y+float64(row)*module, float64(length)*module, module)
+	})
+}
+
+func qrCaption(index int, total int) string {
+	return fmt.Sprintf("QR code %d of %d", index, total)
+}
+
+// wrap splits the text into lines by words, since the label may be arbitrarily long
+func wrap(text string, limit int) []string {
+	var lines []string
+	for len(text) > limit {
+		cut := strings.LastIndex(text[:limit], " ")
+		if cut <= 0 {
+			cut = limit
+		}
+		lines = append(lines, text[:cut])
+		text = strings.TrimSpace(text[cut:])
+	}
+	return append(lines, text)
+}
@@ -0,0 +1,54 @@
+package paper
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/d347h-eth/aesgcm/internal/domain"
+)
+
+func TestRender(t *testing.T) {
+	payload := make([]byte, 6000)
+	rand.Read(payload)
+	sheet := &domain.PaperSheet{
+		Label:         strings.Repeat("very long label ", 20),
+		Created:       time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		KeyDerivation: domain.KeyDerivation{Salt: make([]byte, 16), Iterations: 1000, Length: 32},
+		NonceLength:   12,
+		Lines:         []string{"-----BEGIN AESGCM ENCRYPTED MESSAGE-----", "-----END AESGCM ENCRYPTED MESSAGE-----"},
+		QRData:        []byte(base64.StdEncoding.EncodeToString(payload)),
+	}
+	for _, pageSize := range []string{PAGE_A4, PAGE_LETTER} {
+		document, err := NewRenderer(Config{PageSize: pageSize, QRLevel: 1}).Render(sheet)
+		if err != nil {
+			t.Fatalf("failed to render %s sheet: %s", pageSize, err)
+		}
+		if !bytes.HasPrefix(document, []byte("%PDF-1.4")) {
+			t.Fatalf("the %s sheet isn't a PDF document", pageSize)
+		}
+		// the payload exceeds a single QR code, every part has to be printed
+		for _, caption := range []string{"QR code 1 of 4", "QR code 4 of 4", "Take photos of all 4 QR codes"} {
+			if !bytes.Contains(document, []byte(caption)) {
+				t.Fatalf("%q is missing on the %s sheet", caption, pageSize)
+			}
+		}
+		if !bytes.Contains(document, []byte("/Count 2")) {
+			t.Fatalf("the codes haven't been continued on the next page of the %s sheet", pageSize)
+		}
+	}
+	if _, err := NewRenderer(Config{PageSize: "a3"}).Render(sheet); err == nil {
+		t.Fatalf("expected unsupported page size to fail")
+	}
+}
+
+func TestWrap(t *testing.T) {
+	lines := wrap("the quick brown fox jumps over the lazy dog", 10)
+	want := []string{"the quick", "brown fox", "jumps", "over the", "lazy dog"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected lines: got %q, want %q", lines, want)
+	}
+}
@@ -0,0 +1,138 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// FONT_HELVETICA is the standard sans-serif font
+const FONT_HELVETICA = "Helvetica"
+
+// FONT_HELVETICA_BOLD is the standard bold sans-serif font
+const FONT_HELVETICA_BOLD = "Helvetica-Bold"
+
+// FONT_COURIER is the standard monospaced font
+const FONT_COURIER = "Courier"
+
+// MM is the amount of points in a millimeter
+const MM = 72 / 25.4
+
+var fonts = []string{FONT_HELVETICA, FONT_HELVETICA_BOLD, FONT_COURIER}
+
+type (
+	// Document is a minimal PDF writer which supports text in standard fonts and filled rectangles,
+	// coordinates are in points with the origin at the top-left corner of the page
+	Document struct {
+		width  float64
+		height float64
+		pages  []*Page
+	}
+
+	// Page ...
+	Page struct {
+		height  float64
+		content bytes.Buffer
+	}
+)
+
+// NewDocument creates a new document with the page size in points
+func NewDocument(width float64, height float64) *Document {
+	return &Document{width: width, height: height}
+}
+
+// AddPage ...
+func (d *Document) AddPage() *Page {
+	page := &Page{height: d.height}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// Text draws a line of text, y is the baseline position
+func (p *Page) Text(x float64, y float64, font string, size float64, text string) {
+	fontIndex := 0
+	for i, name := range fonts {
+		if name == font {
+			fontIndex = i
+		}
+	}
+	fmt.Fprintf(&p.content, "BT /F%d %s Tf %s %s Td (%s) Tj ET\n",
+		fontIndex+1, number(size), number(x), number(p.height-y), escape(text))
+}
+
+// Rect draws a filled black rectangle
+func (p *Page) Rect(x float64, y float64, width float64, height float64) {
+	fmt.Fprintf(&p.content, "%s %s %s %s re f\n",
+		number(x), number(p.height-y-height), number(width), number(height))
+}
+
+// Line draws a thin black line
+func (p *Page) Line(x1 float64, y1 float64, x2 float64, y2 float64) {
+	fmt.Fprintf(&p.content, "0.5 w %s %s m %s %s l S\n",
+		number(x1), number(p.height-y1), number(x2), number(p.height-y2))
+}
+
+// Bytes serializes the document
+func (d *Document) Bytes() []byte {
+	var output bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, output.Len())
+		fmt.Fprintf(&output, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+	output.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	// 1: catalog, 2: pages, 3..: fonts, then a page and its content per page
+	fontsStart := 3
+	pagesStart := fontsStart + len(fonts)
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", pagesStart+i*2)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	fontResources := make([]string, len(fonts))
+	for i, font := range fonts {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", font))
+		fontResources[i] = fmt.Sprintf("/F%d %d 0 R", i+1, fontsStart+i)
+	}
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] "+
+			"/Resources << /Font << %s >> >> /Contents %d 0 R >>",
+			number(d.width), number(d.height), strings.Join(fontResources, " "), pagesStart+i*2+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.content.Len(), page.content.String()))
+	}
+	xref := output.Len()
+	fmt.Fprintf(&output, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&output, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&output, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return output.Bytes()
+}
+
+// escape converts text into a PDF string in WinAnsiEncoding, unsupported characters are replaced
+func escape(text string) string {
+	var output strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			output.WriteByte('\\')
+			output.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			output.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&output, "\\%03o", r)
+		default:
+			output.WriteByte('?')
+		}
+	}
+	return output.String()
+}
+
+func number(value float64) string {
+	formatted := strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.3f", value), "0"), ".")
+	if formatted == "-0" || formatted == "" {
+		return "0"
+	}
+	return formatted
+}
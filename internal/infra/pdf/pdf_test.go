@@ -0,0 +1,45 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestDocument(t *testing.T) {
+	document := NewDocument(595.28, 841.89)
+	page := document.AddPage()
+	page.Text(40, 56, FONT_HELVETICA_BOLD, 16, "Backup (copy) \\ ünïcode ✓")
+	page.Rect(10, 20, 30, 40)
+	document.AddPage().Line(0, 0, 100, 100)
+	output := document.Bytes()
+
+	if !bytes.Contains(output, []byte(`(Backup \(copy\) \\ \374n\357code ?) Tj`)) {
+		t.Fatalf("text hasn't been escaped:\n%s", output)
+	}
+	// y axis is flipped, so the rectangle's bottom-left corner is at 841.89-20-40
+	if !bytes.Contains(output, []byte("10 781.89 30 40 re f")) {
+		t.Fatalf("unexpected rectangle:\n%s", output)
+	}
+	if !bytes.Contains(output, []byte("/Count 2")) {
+		t.Fatalf("unexpected page count:\n%s", output)
+	}
+	// every cross-reference entry has to point at its object
+	xref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(output)
+	if xref == nil {
+		t.Fatalf("missing startxref")
+	}
+	start, _ := strconv.Atoi(string(xref[1]))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(output[start:], -1)
+	if len(entries) == 0 {
+		t.Fatalf("empty cross-reference table")
+	}
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if want := fmt.Sprintf("%d 0 obj", i+1); !bytes.HasPrefix(output[offset:], []byte(want)) {
+			t.Fatalf("cross-reference entry %d doesn't point at %q", i+1, want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+//go:build !unix
+
+package process
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+)
+
+// execute runs the command as a child process where the process can't be replaced,
+// the signals are forwarded to the child and its exit code is propagated
+func execute(path string, args []string, env []string) error {
+	cmd := exec.Command(path, args[1:]...)
+	cmd.Env = env
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to execute %q: %w", path, err)
+	}
+	go func() {
+		for sig := range signals {
+			cmd.Process.Signal(sig)
+		}
+	}()
+	err := cmd.Wait()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		os.Exit(0)
+	case errors.As(err, &exitErr) && exitErr.ExitCode() >= 0:
+		os.Exit(exitErr.ExitCode())
+	}
+	return fmt.Errorf("failed to execute %q: %w", path, err)
+}
@@ -0,0 +1,17 @@
+//go:build unix
+
+package process
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// execute replaces the current process with the command, so it keeps the PID,
+// receives the signals directly and its exit code is the exit code of the tool
+func execute(path string, args []string, env []string) error {
+	if err := syscall.Exec(path, args, env); err != nil {
+		return fmt.Errorf("failed to execute %q: %w", path, err)
+	}
+	return nil
+}
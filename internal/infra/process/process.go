@@ -0,0 +1,51 @@
+package process
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/d347h-eth/aesgcm/internal/domain"
+)
+
+type (
+	// Process is a component responsible for running commands with additional environment variables
+	Process struct{}
+)
+
+// NewProcess ...
+func NewProcess() *Process {
+	return &Process{}
+}
+
+// Exec runs the command with the variables merged into the current environment and exits with its exit code,
+// on success it never returns
+func (p Process) Exec(args []string, vars []domain.EnvVar) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no command specified")
+	}
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return fmt.Errorf("command not found: %w", err)
+	}
+	return execute(path, args, environ(vars))
+}
+
+// environ merges the variables into the current environment, the variables take precedence
+func environ(vars []domain.EnvVar) []string {
+	overridden := make(map[string]bool, len(vars))
+	for _, v := range vars {
+		overridden[v.Name] = true
+	}
+	var env []string
+	for _, entry := range os.Environ() {
+		if name, _, _ := strings.Cut(entry, "="); !overridden[name] {
+			env = append(env, entry)
+		}
+	}
+	for _, v := range vars {
+		env = append(env, v.Name+"="+v.Value)
+	}
+	return env
+}
@@ -0,0 +1,163 @@
+package qrdecoder
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	_ "image/jpeg" // register JPEG decoder
+	_ "image/png"  // register PNG decoder
+
+	"github.com/makiuchi-d/gozxing"
+	multiqrcode "github.com/makiuchi-d/gozxing/multi/qrcode"
+	"github.com/makiuchi-d/gozxing/qrcode"
+)
+
+// maxScanDimension is the size phone photos are downscaled to, since the detector
+// works better when the modules aren't too large and the sensor noise is averaged out
+const maxScanDimension = 1600
+
+type (
+	// QRDecoder is a component responsible for locating and decoding QR codes in images
+	QRDecoder struct{}
+)
+
+// NewQRDecoder ...
+func NewQRDecoder() *QRDecoder {
+	return &QRDecoder{}
+}
+
+// IsImage checks if the data is an image in one of the supported formats
+func (d QRDecoder) IsImage(data []byte) bool {
+	_, _, err := image.DecodeConfig(bytes.NewReader(data))
+	return err == nil
+}
+
+// Decode locates all QR codes in the image and returns their payloads,
+// rotated, skewed and noisy images (e.g. phone photos of a printed sheet) are tolerated,
+// every frame of an animated GIF is scanned
+func (d QRDecoder) Decode(data []byte) ([][]byte, error) {
+	if animation, err := gif.DecodeAll(bytes.NewReader(data)); err == nil && len(animation.Image) > 1 {
+		var payloads [][]byte
+		for _, frame := range animation.Image {
+			if decoded, err := decodeImage(frame); err == nil {
+				payloads = append(payloads, decoded...)
+			}
+		}
+		if len(payloads) == 0 {
+			return nil, fmt.Errorf("no QR code has been found in the animation")
+		}
+		return payloads, nil
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return decodeImage(img)
+}
+
+func decodeImage(img image.Image) ([][]byte, error) {
+	gray := toGray(img)
+	candidates := []image.Image{gray}
+	if scaled := downscale(gray, maxScanDimension); scaled != nil {
+		candidates = append(candidates, scaled)
+	}
+	hints := map[gozxing.DecodeHintType]interface{}{
+		gozxing.DecodeHintType_TRY_HARDER: true,
+		// Latin-1 maps every byte to a single character, so binary payloads survive decoding
+		gozxing.DecodeHintType_CHARACTER_SET: "ISO-8859-1",
+		gozxing.DecodeHintType_ALSO_INVERTED: true,
+	}
+	for _, candidate := range candidates {
+		source := gozxing.NewLuminanceSourceFromImage(candidate)
+		binarizers := []gozxing.Binarizer{
+			gozxing.NewHybridBinarizer(source),
+			gozxing.NewGlobalHistgramBinarizer(source),
+		}
+		for _, binarizer := range binarizers {
+			bitmap, err := gozxing.NewBinaryBitmap(binarizer)
+			if err != nil {
+				return nil, err
+			}
+			if payloads := decodeBitmap(bitmap, hints); len(payloads) > 0 {
+				return payloads, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no QR code has been found in the image")
+}
+
+func decodeBitmap(bitmap *gozxing.BinaryBitmap, hints map[gozxing.DecodeHintType]interface{}) [][]byte {
+	var results []*gozxing.Result
+	if multiple, err := multiqrcode.NewQRCodeMultiReader().DecodeMultiple(bitmap, hints); err == nil {
+		results = multiple
+	}
+	if len(results) == 0 {
+		// the single code reader is more tolerant to perspective distortion
+		if single, err := qrcode.NewQRCodeReader().Decode(bitmap, hints); err == nil {
+			results = append(results, single)
+		}
+	}
+	var payloads [][]byte
+	seen := make(map[string]bool)
+	for _, result := range results {
+		text := result.GetText()
+		if seen[text] {
+			continue
+		}
+		seen[text] = true
+		payloads = append(payloads, latin1Bytes(text))
+	}
+	return payloads
+}
+
+// latin1Bytes is the reverse of ISO-8859-1 decoding
+func latin1Bytes(text string) []byte {
+	output := make([]byte, 0, len(text))
+	for _, r := range text {
+		output = append(output, byte(r))
+	}
+	return output
+}
+
+func toGray(img image.Image) *image.Gray {
+	bounds := img.Bounds()
+	gray := image.NewGray(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			gray.Set(x-bounds.Min.X, y-bounds.Min.Y, color.GrayModel.Convert(img.At(x, y)))
+		}
+	}
+	return gray
+}
+
+// downscale averages pixel boxes to fit the image into the limit, nil is returned if it fits already
+func downscale(img *image.Gray, limit int) *image.Gray {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	factor := (max(width, height) + limit - 1) / limit
+	if factor <= 1 {
+		return nil
+	}
+	scaled := image.NewGray(image.Rect(0, 0, width/factor, height/factor))
+	for y := 0; y < height/factor; y++ {
+		for x := 0; x < width/factor; x++ {
+			sum := 0
+			for dy := 0; dy < factor; dy++ {
+				row := img.Pix[(y*factor+dy)*img.Stride:]
+				for dx := 0; dx < factor; dx++ {
+					sum += int(row[x*factor+dx])
+				}
+			}
+			scaled.Pix[y*scaled.Stride+x] = uint8(sum / (factor * factor))
+		}
+	}
+	return scaled
+}
+
+func max(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
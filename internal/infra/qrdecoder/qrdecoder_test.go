@@ -0,0 +1,106 @@
+package qrdecoder
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/skip2/go-qrcode"
+)
+
+func TestDecode(t *testing.T) {
+	payload := []byte("AESGCM-test-payload-0123456789")
+	binary := []byte{0x00, 0xff, 0x80, 0x7f, 0xd9, 0xd9, 0xf7, 0x0a}
+	decoder := NewQRDecoder()
+
+	if decoder.IsImage(payload) {
+		t.Fatal("text payload has been recognized as an image")
+	}
+
+	t.Run("png", func(t *testing.T) {
+		data := encodePNG(t, renderQR(t, payload))
+		if !decoder.IsImage(data) {
+			t.Fatal("PNG image has not been recognized")
+		}
+		assertDecoded(t, decoder, data, payload)
+	})
+
+	t.Run("binary payload", func(t *testing.T) {
+		assertDecoded(t, decoder, encodePNG(t, renderQR(t, binary)), binary)
+	})
+
+	t.Run("photo", func(t *testing.T) {
+		// rotated, skewed and noisy JPEG simulates a phone photo of a printed sheet
+		photo := distort(renderQR(t, payload), 17*math.Pi/180, 0.15)
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, photo, &jpeg.Options{Quality: 60}); err != nil {
+			t.Fatal(err)
+		}
+		assertDecoded(t, decoder, buf.Bytes(), payload)
+	})
+
+	t.Run("no code", func(t *testing.T) {
+		blank := image.NewGray(image.Rect(0, 0, 200, 200))
+		if _, err := decoder.Decode(encodePNG(t, blank)); err == nil {
+			t.Fatal("expected an error for the image without QR code")
+		}
+	})
+}
+
+func assertDecoded(t *testing.T, decoder *QRDecoder, data []byte, expected []byte) {
+	t.Helper()
+	payloads, err := decoder.Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payloads) != 1 || !bytes.Equal(payloads[0], expected) {
+		t.Fatalf("unexpected payloads: %q", payloads)
+	}
+}
+
+func renderQR(t *testing.T, data []byte) image.Image {
+	t.Helper()
+	code, err := qrcode.New(string(data), qrcode.Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code.Image(400)
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// distort rotates the image by the angle, shears it horizontally and adds noise
+func distort(src image.Image, angle float64, shear float64) image.Image {
+	bounds := src.Bounds()
+	size := bounds.Dx() * 3 / 2
+	dst := image.NewGray(image.Rect(0, 0, size, size))
+	cx, cy := float64(bounds.Dx())/2, float64(bounds.Dy())/2
+	sin, cos := math.Sin(angle), math.Cos(angle)
+	rnd := rand.New(rand.NewSource(1))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx, dy := float64(x)-float64(size)/2, float64(y)-float64(size)/2
+			dx -= shear * dy
+			sx, sy := cos*dx+sin*dy+cx, -sin*dx+cos*dy+cy
+			value := 255.0
+			if sx >= 0 && sy >= 0 && sx < float64(bounds.Dx()) && sy < float64(bounds.Dy()) {
+				value = float64(color.GrayModel.Convert(src.At(int(sx), int(sy))).(color.Gray).Y)
+			}
+			value += rnd.NormFloat64() * 25
+			dst.SetGray(x, y, color.Gray{Y: uint8(math.Max(0, math.Min(255, value)))})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,177 @@
+package qrencoder
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// ANIMATION_GIF selects animated GIF output
+const ANIMATION_GIF = "gif"
+
+// ANIMATION_APNG selects animated PNG output
+const ANIMATION_APNG = "apng"
+
+// AnimationFormats lists supported animation formats
+var AnimationFormats = []string{ANIMATION_GIF, ANIMATION_APNG}
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// EncodeAnimation generates a looped animation with one QR code per frame
+func (e *QREncoder) EncodeAnimation(frames [][]byte) ([]byte, error) {
+	images := make([]*image.Paletted, 0, len(frames))
+	for i, frame := range frames {
+		code, err := qrcode.New(string(frame), qrcode.RecoveryLevel(e.cfg.Level))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode frame %d: %w", i+1, err)
+		}
+		images = append(images, paletted(code.Image(e.cfg.Size)))
+	}
+	// frames may differ in QR version, so all of them are centered on the largest canvas
+	images = fitCanvas(images)
+
+	var buf bytes.Buffer
+	var err error
+	switch e.cfg.AnimationFormat {
+	case ANIMATION_APNG:
+		err = writeAPNG(&buf, images, e.cfg.FrameDelay)
+	case ANIMATION_GIF, "":
+		delays := make([]int, len(images))
+		for i := range delays {
+			delays[i] = e.cfg.FrameDelay / 10 // GIF delays are in 1/100 s
+		}
+		err = gif.EncodeAll(&buf, &gif.GIF{Image: images, Delay: delays})
+	default:
+		err = fmt.Errorf("unsupported animation format: %s", e.cfg.AnimationFormat)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func paletted(img image.Image) *image.Paletted {
+	if p, ok := img.(*image.Paletted); ok {
+		return p
+	}
+	p := image.NewPaletted(img.Bounds(), color.Palette{color.White, color.Black})
+	draw.Draw(p, p.Bounds(), img, img.Bounds().Min, draw.Src)
+	return p
+}
+
+func fitCanvas(images []*image.Paletted) []*image.Paletted {
+	size := 0
+	for _, img := range images {
+		if img.Bounds().Dx() > size {
+			size = img.Bounds().Dx()
+		}
+	}
+	output := make([]*image.Paletted, len(images))
+	for i, img := range images {
+		if img.Bounds().Dx() == size && img.Bounds().Min == (image.Point{}) {
+			output[i] = img
+			continue
+		}
+		canvas := image.NewPaletted(image.Rect(0, 0, size, size), img.Palette)
+		draw.Draw(canvas, canvas.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		offset := (size - img.Bounds().Dx()) / 2
+		draw.Draw(canvas, img.Bounds().Sub(img.Bounds().Min).Add(image.Pt(offset, offset)), img, img.Bounds().Min, draw.Src)
+		output[i] = canvas
+	}
+	return output
+}
+
+// writeAPNG assembles an animated PNG from PNG encoded frames: the first frame's IDAT chunks
+// become the default image, the rest are stored as fdAT chunks, delay is in milliseconds
+func writeAPNG(w io.Writer, images []*image.Paletted, delay int) error {
+	var chunks [][]byte
+	sequence := uint32(0)
+	for i, img := range images {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return err
+		}
+		header, data, err := splitPNG(buf.Bytes())
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			chunks = append(chunks, header, chunk("acTL", be32(uint32(len(images)), 0)))
+		}
+		bounds := img.Bounds()
+		fcTL := be32(sequence, uint32(bounds.Dx()), uint32(bounds.Dy()), 0, 0)
+		fcTL = binary.BigEndian.AppendUint16(fcTL, uint16(delay))
+		fcTL = binary.BigEndian.AppendUint16(fcTL, 1000)
+		fcTL = append(fcTL, 0, 0) // dispose none, blend source
+		chunks = append(chunks, chunk("fcTL", fcTL))
+		sequence++
+		for _, idat := range data {
+			if i == 0 {
+				chunks = append(chunks, chunk("IDAT", idat))
+				continue
+			}
+			chunks = append(chunks, chunk("fdAT", append(be32(sequence), idat...)))
+			sequence++
+		}
+	}
+	chunks = append(chunks, chunk("IEND", nil))
+
+	if _, err := w.Write(pngSignature); err != nil {
+		return err
+	}
+	for _, c := range chunks {
+		if _, err := w.Write(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitPNG returns the serialized chunks preceding image data (IHDR, PLTE) and the data of IDAT chunks
+func splitPNG(data []byte) ([]byte, [][]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, nil, fmt.Errorf("invalid PNG signature")
+	}
+	var header []byte
+	var idats [][]byte
+	for rest := data[len(pngSignature):]; len(rest) >= 12; {
+		length := binary.BigEndian.Uint32(rest)
+		if int(length)+12 > len(rest) {
+			return nil, nil, fmt.Errorf("truncated PNG chunk")
+		}
+		name := string(rest[4:8])
+		switch name {
+		case "IDAT":
+			idats = append(idats, rest[8:8+length])
+		case "IEND":
+		default:
+			header = append(header, rest[:length+12]...)
+		}
+		rest = rest[length+12:]
+	}
+	return header, idats, nil
+}
+
+func chunk(name string, data []byte) []byte {
+	output := be32(uint32(len(data)))
+	output = append(output, name...)
+	output = append(output, data...)
+	return binary.BigEndian.AppendUint32(output, crc32.ChecksumIEEE(output[4:]))
+}
+
+func be32(values ...uint32) []byte {
+	output := make([]byte, 0, 4*len(values))
+	for _, value := range values {
+		output = binary.BigEndian.AppendUint32(output, value)
+	}
+	return output
+}
@@ -1,9 +1,44 @@
 package qrencoder
 
 import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strings"
+
+	"github.com/d347h-eth/aesgcm/internal/domain"
+
 	"github.com/skip2/go-qrcode"
 )
 
+// FORMAT_PNG renders QR codes as PNG images of the configured size in pixels
+const FORMAT_PNG = "png"
+
+// FORMAT_SVG renders QR codes as SVG images with the configured module size in millimeters
+const FORMAT_SVG = "svg"
+
+// FORMAT_PDF renders QR codes as a PDF document, one code per page, with the configured module size in millimeters
+const FORMAT_PDF = "pdf"
+
+// FORMAT_TERMINAL renders QR codes as text with Unicode half blocks
+const FORMAT_TERMINAL = "terminal"
+
+// Formats lists supported output formats
+var Formats = []string{FORMAT_PNG, FORMAT_SVG, FORMAT_PDF, FORMAT_TERMINAL}
+
+// capacities is the byte mode capacity of QR code version 40 per error recovery level
+var capacities = []int{2953, 2331, 1663, 1273}
+
+// alphanumericCapacities is the alphanumeric mode capacity of QR code version 40 per error recovery level
+var alphanumericCapacities = []int{4296, 3391, 2420, 1852}
+
+// alphanumericCharset is the character set of QR code alphanumeric mode
+const alphanumericCharset = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
 type (
 	// QREncoderConfig ...
 	QREncoderConfig struct {
@@ -11,6 +46,18 @@ type (
 		Level int
 		// Size is generated image size in pixels
 		Size int
+		// Tiled places all QR codes of a split payload on a single image
+		Tiled bool
+		// AnimationFormat is the format of animated output (gif or apng)
+		AnimationFormat string
+		// FrameDelay is the duration of a single animation frame in milliseconds
+		FrameDelay int
+		// Format is the output format (png, svg, pdf, terminal)
+		Format string
+		// ModuleSize is the size of a single module of vector outputs in millimeters
+		ModuleSize float64
+		// Inverted draws dark modules with the terminal foreground color instead of the light ones
+		Inverted bool
 	}
 
 	// QREncoder ...
@@ -24,13 +71,121 @@ func NewQREncoder(cfg QREncoderConfig) *QREncoder {
 	return &QREncoder{cfg}
 }
 
-// Encode generates QR code image
-func (e *QREncoder) Encode(data []byte) ([]byte, error) {
-	var imgBytes []byte
-	imgBytes, err := qrcode.Encode(
-		string(data),
-		qrcode.RecoveryLevel(e.cfg.Level),
-		e.cfg.Size,
-	)
-	return imgBytes, err
+// Extension returns the file extension of the output format,
+// it's empty for the terminal output which isn't supposed to be saved
+func (e *QREncoder) Extension() string {
+	switch e.cfg.Format {
+	case FORMAT_TERMINAL:
+		return ""
+	case "":
+		return "." + FORMAT_PNG
+	default:
+		return "." + e.cfg.Format
+	}
+}
+
+// Encode generates QR code images, payloads exceeding the capacity of a single QR code
+// are split into parts with sequence headers, one image per part unless tiling is enabled
+// (PDF output always contains all the parts as pages of a single document)
+func (e *QREncoder) Encode(data []byte) ([][]byte, error) {
+	codes, err := Codes(data, e.cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+	switch e.cfg.Format {
+	case FORMAT_PNG, "":
+		return e.encodePNG(codes)
+	case FORMAT_SVG:
+		output := make([][]byte, 0, len(codes))
+		for _, code := range codes {
+			output = append(output, renderSVG(code.Bitmap(), e.cfg.ModuleSize))
+		}
+		return output, nil
+	case FORMAT_PDF:
+		return [][]byte{renderPDF(codes, e.cfg.ModuleSize)}, nil
+	case FORMAT_TERMINAL:
+		output := make([][]byte, 0, len(codes))
+		for _, code := range codes {
+			output = append(output, renderTerminal(code.Bitmap(), e.cfg.Inverted))
+		}
+		return output, nil
+	default:
+		return nil, fmt.Errorf("unsupported QR code format: %s", e.cfg.Format)
+	}
+}
+
+// Codes encodes the payload with the error recovery level,
+// it's split into parts with sequence headers if it exceeds the capacity of a single QR code
+func Codes(data []byte, level int) ([]*qrcode.QRCode, error) {
+	capacity := capacities[level]
+	if alphanumeric(data) {
+		capacity = alphanumericCapacities[level]
+	}
+	if len(data) <= capacity {
+		code, err := qrcode.New(string(data), qrcode.RecoveryLevel(level))
+		if err != nil {
+			return nil, err
+		}
+		return []*qrcode.QRCode{code}, nil
+	}
+
+	// the header length depends on the number of parts, so it's estimated with a generous upper bound
+	parts := domain.SplitQRParts(data, capacity-domain.QRPartHeaderLength(len(data)))
+	codes := make([]*qrcode.QRCode, 0, len(parts))
+	for _, part := range parts {
+		code, err := qrcode.New(string(part.Bytes()), qrcode.RecoveryLevel(level))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode part %d of %d: %w", part.Index, part.Total, err)
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+func (e *QREncoder) encodePNG(codes []*qrcode.QRCode) ([][]byte, error) {
+	images := make([]image.Image, 0, len(codes))
+	for _, code := range codes {
+		images = append(images, code.Image(e.cfg.Size))
+	}
+	if e.cfg.Tiled && len(images) > 1 {
+		images = []image.Image{tile(images)}
+	}
+	output := make([][]byte, 0, len(images))
+	for _, img := range images {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+		output = append(output, buf.Bytes())
+	}
+	return output, nil
+}
+
+// tile arranges the images into a square grid, the quiet zone of each code separates them
+func tile(images []image.Image) image.Image {
+	columns := int(math.Ceil(math.Sqrt(float64(len(images)))))
+	rows := (len(images) + columns - 1) / columns
+	cell := 0
+	for _, img := range images {
+		if size := img.Bounds().Dx(); size > cell {
+			cell = size
+		}
+	}
+	sheet := image.NewGray(image.Rect(0, 0, columns*cell, rows*cell))
+	draw.Draw(sheet, sheet.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	for i, img := range images {
+		offset := image.Pt(i%columns*cell, i/columns*cell)
+		draw.Draw(sheet, img.Bounds().Add(offset), img, img.Bounds().Min, draw.Src)
+	}
+	return sheet
+}
+
+// alphanumeric checks if the data fits QR code alphanumeric mode, which stores 5.5 bits per character
+func alphanumeric(data []byte) bool {
+	for _, b := range data {
+		if strings.IndexByte(alphanumericCharset, b) < 0 {
+			return false
+		}
+	}
+	return true
 }
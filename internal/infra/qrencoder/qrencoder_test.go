@@ -0,0 +1,156 @@
+package qrencoder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/d347h-eth/aesgcm/internal/domain"
+	"github.com/d347h-eth/aesgcm/internal/infra/qrdecoder"
+)
+
+func TestEncodeSplit(t *testing.T) {
+	payload := make([]byte, 6000)
+	rand.New(rand.NewSource(1)).Read(payload)
+	encoder := NewQREncoder(QREncoderConfig{Level: 1, Size: -3, Format: FORMAT_PNG})
+
+	images, err := encoder.Encode(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(images) != 3 {
+		t.Fatalf("unexpected number of images: %d", len(images))
+	}
+
+	// every image holds a single part and the parts scanned in reverse order rejoin into the payload
+	decoder := qrdecoder.NewQRDecoder()
+	var parts []domain.QRPart
+	for i := len(images) - 1; i >= 0; i-- {
+		decoded, err := decoder.Decode(images[i])
+		if err != nil {
+			t.Fatalf("failed to decode image %d: %s", i+1, err)
+		}
+		if len(decoded) != 1 {
+			t.Fatalf("unexpected number of codes on image %d: %d", i+1, len(decoded))
+		}
+		part, ok, err := domain.ParseQRPart(decoded[0])
+		if !ok || err != nil {
+			t.Fatalf("image %d doesn't hold a QR code part: %v", i+1, err)
+		}
+		parts = append(parts, *part)
+	}
+	joined, err := domain.JoinQRParts(parts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(joined, payload) {
+		t.Fatal("joined payload doesn't match")
+	}
+
+	var missing *domain.MissingQRPartsError
+	if _, err := domain.JoinQRParts(parts[1:]); !errors.As(err, &missing) || missing.Total != 3 {
+		t.Fatalf("expected MissingQRPartsError, got: %v", err)
+	}
+}
+
+func TestVectorFormats(t *testing.T) {
+	payload := []byte("AESGCM vector output test")
+	codes, err := Codes(payload, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bitmap := codes[0].Bitmap()
+
+	t.Run("svg", func(t *testing.T) {
+		images, err := NewQREncoder(QREncoderConfig{Level: 1, Format: FORMAT_SVG, ModuleSize: 0.5}).Encode(payload)
+		if err != nil || len(images) != 1 {
+			t.Fatalf("failed to encode SVG: %v", err)
+		}
+		svg := string(images[0])
+		// the physical size is the number of modules including the quiet zone times the module size
+		size := fmt.Sprintf(`width="%smm" height="%smm" viewBox="0 0 %d %d"`,
+			number(float64(len(bitmap))*0.5), number(float64(len(bitmap))*0.5), len(bitmap), len(bitmap))
+		if !strings.Contains(svg, size) {
+			t.Fatalf("unexpected SVG size, want %s:\n%s", size, svg)
+		}
+		// the runs of the path cover exactly the dark modules
+		path := svg[strings.Index(svg, ` d="`)+4:]
+		path = path[:strings.IndexByte(path, '"')]
+		drawn := blankBitmap(len(bitmap))
+		for _, run := range strings.Split(strings.TrimSuffix(path, "z"), "z") {
+			var col, row, length, back int
+			if _, err := fmt.Sscanf(run, "M%d %dh%dv1h-%d", &col, &row, &length, &back); err != nil || back != length {
+				t.Fatalf("malformed path run %q: %v", run, err)
+			}
+			for i := 0; i < length; i++ {
+				drawn[row][col+i] = true
+			}
+		}
+		assertBitmap(t, drawn, bitmap)
+	})
+
+	t.Run("pdf", func(t *testing.T) {
+		large := bytes.Repeat([]byte{0xa5}, 4000)
+		documents, err := NewQREncoder(QREncoderConfig{Level: 1, Format: FORMAT_PDF, ModuleSize: 0.5}).Encode(large)
+		if err != nil || len(documents) != 1 {
+			t.Fatalf("failed to encode PDF: %v", err)
+		}
+		document := documents[0]
+		if !bytes.HasPrefix(document, []byte("%PDF-")) || !bytes.Contains(document, []byte("/Count 2")) ||
+			!bytes.Contains(document, []byte("Part 2 of 2, Module size 0.5 mm")) {
+			t.Fatalf("expected a PDF document with a page per part")
+		}
+	})
+
+	t.Run("terminal", func(t *testing.T) {
+		for _, inverted := range []bool{false, true} {
+			output, err := NewQREncoder(QREncoderConfig{Level: 1, Format: FORMAT_TERMINAL, Inverted: inverted}).Encode(payload)
+			if err != nil || len(output) != 1 {
+				t.Fatalf("failed to encode terminal output: %v", err)
+			}
+			// every line holds two rows of modules, the half blocks are read back into the bitmap
+			lines := strings.Split(strings.TrimSuffix(string(output[0]), "\n"), "\n")
+			if len(lines) != (len(bitmap)+1)/2 {
+				t.Fatalf("unexpected number of lines: %d", len(lines))
+			}
+			decoded := blankBitmap(len(bitmap))
+			for i, line := range lines {
+				col := 0
+				for _, char := range line {
+					upper, lower := char == '█' || char == '▀', char == '█' || char == '▄'
+					decoded[2*i][col] = upper == inverted
+					if 2*i+1 < len(bitmap) {
+						decoded[2*i+1][col] = lower == inverted
+					}
+					col++
+				}
+				if col != len(bitmap) {
+					t.Fatalf("unexpected width of line %d: %d", i+1, col)
+				}
+			}
+			assertBitmap(t, decoded, bitmap)
+		}
+	})
+}
+
+func blankBitmap(size int) [][]bool {
+	bitmap := make([][]bool, size)
+	for i := range bitmap {
+		bitmap[i] = make([]bool, size)
+	}
+	return bitmap
+}
+
+func assertBitmap(t *testing.T, actual [][]bool, expected [][]bool) {
+	t.Helper()
+	for row := range expected {
+		for col := range expected[row] {
+			if actual[row][col] != expected[row][col] {
+				t.Fatalf("module at row %d, column %d doesn't match", row, col)
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package qrencoder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/d347h-eth/aesgcm/internal/infra/pdf"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// A4 page is used for PDF output unless the code doesn't fit into it
+const (
+	pdfPageWidth  = 210 * pdf.MM
+	pdfPageHeight = 297 * pdf.MM
+	pdfMargin     = 15 * pdf.MM
+)
+
+// Runs calls the function for every horizontal run of dark modules,
+// merging them keeps vector outputs small
+func Runs(bitmap [][]bool, fn func(row int, col int, length int)) {
+	for row := range bitmap {
+		for col := 0; col < len(bitmap[row]); col++ {
+			if !bitmap[row][col] {
+				continue
+			}
+			start := col
+			for col+1 < len(bitmap[row]) && bitmap[row][col+1] {
+				col++
+			}
+			fn(row, start, col-start+1)
+		}
+	}
+}
+
+// renderSVG draws the code in module units scaled to the exact physical size,
+// the bitmap includes the quiet zone
+func renderSVG(bitmap [][]bool, moduleSize float64) []byte {
+	size := len(bitmap)
+	var path strings.Builder
+	Runs(bitmap, func(row int, col int, length int) {
+		fmt.Fprintf(&path, "M%d %dh%dv1h-%dz", col, row, length, length)
+	})
+	physical := number(float64(size) * moduleSize)
+	return []byte(fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" width="%smm" height="%smm" viewBox="0 0 %d %d" shape-rendering="crispEdges">
+<rect width="%d" height="%d" fill="#fff"/>
+<path fill="#000" d="%s"/>
+</svg>
+`, physical, physical, size, size, size, size, path.String()))
+}
+
+// renderPDF places every code on its own page at the exact physical size
+func renderPDF(codes []*qrcode.QRCode, moduleSize float64) []byte {
+	module := moduleSize * pdf.MM
+	width, height := pdfPageWidth, pdfPageHeight
+	for _, code := range codes {
+		size := float64(len(code.Bitmap())) * module
+		if size+2*pdfMargin > width {
+			width = size + 2*pdfMargin
+		}
+		if size+3*pdfMargin > height {
+			height = size + 3*pdfMargin
+		}
+	}
+	document := pdf.NewDocument(width, height)
+	for i, code := range codes {
+		page := document.AddPage()
+		bitmap := code.Bitmap()
+		x := (width - float64(len(bitmap))*module) / 2
+		Runs(bitmap, func(row int, col int, length int) {
+			page.Rect(x+float64(col)*module, pdfMargin+float64(row)*module, float64(length)*module, module)
+		})
+		caption := fmt.Sprintf("Module size %s mm", number(moduleSize))
+		if len(codes) > 1 {
+			caption = fmt.Sprintf("Part %d of %d, %s", i+1, len(codes), caption)
+		}
+		page.Text(x, pdfMargin+float64(len(bitmap))*module+5*pdf.MM, pdf.FONT_HELVETICA, 9, caption)
+	}
+	return document.Bytes()
+}
+
+// renderTerminal draws two rows of modules per line with Unicode half blocks,
+// by default the light modules are drawn, since terminals usually have dark background
+func renderTerminal(bitmap [][]bool, inverted bool) []byte {
+	drawn := func(row int, col int) bool {
+		if row >= len(bitmap) {
+			return false
+		}
+		return bitmap[row][col] == inverted
+	}
+	var output strings.Builder
+	for row := 0; row < len(bitmap); row += 2 {
+		for col := range bitmap[row] {
+			upper, lower := drawn(row, col), drawn(row+1, col)
+			switch {
+			case upper && lower:
+				output.WriteString("█")
+			case upper:
+				output.WriteString("▀")
+			case lower:
+				output.WriteString("▄")
+			default:
+				output.WriteByte(' ')
+			}
+		}
+		output.WriteByte('\n')
+	}
+	return []byte(output.String())
+}
+
+func number(value float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.3f", value), "0"), ".")
+}
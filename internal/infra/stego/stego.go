@@ -0,0 +1,210 @@
+package stego
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"  // register GIF decoder
+	_ "image/jpeg" // register JPEG decoder
+	"image/png"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// KEY_DERIVATION_ITERATIONS is the amount of PBKDF2 iterations used to derive the key of the pixel permutation
+const KEY_DERIVATION_ITERATIONS = 500000
+
+// keySalt binds the key to this tool, the image dimensions are appended to it
+const keySalt = "aesgcm-stego"
+
+// headerLength is the length of the hidden payload header: 4 bytes of length and 4 bytes of SHA-256
+const headerLength = 8
+
+// channels is the number of color channels carrying hidden bits, the alpha channel isn't touched
+const channels = 3
+
+type (
+	// Stego is a component responsible for hiding data in the least significant bits of images,
+	// the bits are spread over color channels in the order of a pseudorandom permutation keyed with a password,
+	// so without the password the hidden data can't be located
+	Stego struct{}
+)
+
+// NewStego ...
+func NewStego() *Stego {
+	return &Stego{}
+}
+
+// Capacity returns the maximum number of bytes the cover image can hold
+func (s Stego) Capacity(cover []byte) (int, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(cover))
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode cover image: %w", err)
+	}
+	return capacity(config.Width, config.Height), nil
+}
+
+// Embed hides the payload in the cover image and returns PNG image,
+// LSB matching (±1 instead of overwriting the bit) is used to resist histogram based detection
+func (s Stego) Embed(cover []byte, payload []byte, password []byte) ([]byte, error) {
+	img, err := decodeImage(cover)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode cover image: %w", err)
+	}
+	if capacity := capacity(img.Rect.Dx(), img.Rect.Dy()); len(payload) > capacity {
+		return nil, fmt.Errorf("the cover image can hold at most %d bytes, the payload is %d bytes", capacity, len(payload))
+	}
+
+	hash := sha256.Sum256(payload)
+	data := binary.BigEndian.AppendUint32(nil, uint32(len(payload)))
+	data = append(append(data, hash[:4]...), payload...)
+	walk := newSlotWalk(img, password)
+	for _, b := range data {
+		for bit := 7; bit >= 0; bit-- {
+			value := walk.next()
+			if *value&1 != b>>bit&1 {
+				switch {
+				case *value == 0:
+					*value++
+				case *value == 255:
+					*value--
+				case walk.sign():
+					*value++
+				default:
+					*value--
+				}
+			}
+		}
+	}
+
+	var output bytes.Buffer
+	if err := png.Encode(&output, img); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return output.Bytes(), nil
+}
+
+// Extract recovers the payload hidden with the password
+func (s Stego) Extract(data []byte, password []byte) ([]byte, error) {
+	img, err := decodeImage(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	capacity := capacity(img.Rect.Dx(), img.Rect.Dy())
+	if capacity == 0 {
+		return nil, fmt.Errorf("the image is too small to hold hidden data")
+	}
+	walk := newSlotWalk(img, password)
+	read := func(length int) []byte {
+		output := make([]byte, length)
+		for i := range output {
+			for bit := 0; bit < 8; bit++ {
+				output[i] = output[i]<<1 | *walk.next()&1
+			}
+		}
+		return output
+	}
+	header := read(headerLength)
+	// compared unsigned, since the length read with a wrong password mustn't turn negative on 32-bit platforms
+	length := binary.BigEndian.Uint32(header)
+	if uint64(length) > uint64(capacity) {
+		return nil, fmt.Errorf("no hidden data found: the password is wrong or the image has been modified")
+	}
+	payload := read(int(length))
+	if hash := sha256.Sum256(payload); !bytes.Equal(hash[:4], header[4:]) {
+		return nil, fmt.Errorf("no hidden data found: the password is wrong or the image has been modified")
+	}
+	return payload, nil
+}
+
+// capacity is the number of payload bytes an image of the size can hold
+func capacity(width int, height int) int {
+	if size := width*height*channels/8 - headerLength; size > 0 {
+		return size
+	}
+	return 0
+}
+
+// decodeImage decodes the image into 8-bit NRGBA, whose channel values are stored as is
+func decodeImage(data []byte) (*image.NRGBA, error) {
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if img, ok := decoded.(*image.NRGBA); ok {
+		return img, nil
+	}
+	img := image.NewNRGBA(decoded.Bounds())
+	draw.Draw(img, img.Bounds(), decoded, decoded.Bounds().Min, draw.Src)
+	return img, nil
+}
+
+type (
+	// slotWalk visits color channels of the image in the order of a keyed pseudorandom permutation,
+	// Fisher-Yates shuffle is performed lazily, so only the visited slots are stored
+	slotWalk struct {
+		img    *image.NRGBA
+		stream cipher.Stream
+		// signs is a separate stream choosing the direction of LSB matching, so the permutation
+		// doesn't depend on the cover image
+		signs   cipher.Stream
+		swapped map[int]int
+		visited int
+		total   int
+	}
+)
+
+func newSlotWalk(img *image.NRGBA, password []byte) *slotWalk {
+	salt := binary.BigEndian.AppendUint32([]byte(keySalt), uint32(img.Rect.Dx()))
+	salt = binary.BigEndian.AppendUint32(salt, uint32(img.Rect.Dy()))
+	key := pbkdf2.Key(password, salt, KEY_DERIVATION_ITERATIONS, 32, sha512.New)
+	block, _ := aes.NewCipher(key) // the key length is always valid
+	signsIV := make([]byte, aes.BlockSize)
+	signsIV[0] = 1
+	return &slotWalk{
+		img:     img,
+		stream:  cipher.NewCTR(block, make([]byte, aes.BlockSize)),
+		signs:   cipher.NewCTR(block, signsIV),
+		swapped: make(map[int]int),
+		total:   img.Rect.Dx() * img.Rect.Dy() * channels,
+	}
+}
+
+// next returns the channel value of the next slot of the permutation
+func (w *slotWalk) next() *uint8 {
+	i := w.visited
+	j := i + int(w.random()%uint64(w.total-i))
+	slot := w.slot(j)
+	w.swapped[j] = w.slot(i)
+	w.visited++
+
+	pixel, channel := slot/channels, slot%channels
+	x, y := pixel%w.img.Rect.Dx(), pixel/w.img.Rect.Dx()
+	return &w.img.Pix[y*w.img.Stride+x*4+channel]
+}
+
+func (w *slotWalk) slot(i int) int {
+	if slot, ok := w.swapped[i]; ok {
+		return slot
+	}
+	return i
+}
+
+// sign tells if the channel value should be incremented
+func (w *slotWalk) sign() bool {
+	var buffer [1]byte
+	w.signs.XORKeyStream(buffer[:], buffer[:])
+	return buffer[0]&1 == 0
+}
+
+func (w *slotWalk) random() uint64 {
+	var buffer [8]byte
+	w.stream.XORKeyStream(buffer[:], buffer[:])
+	return binary.BigEndian.Uint64(buffer[:])
+}
@@ -0,0 +1,84 @@
+package stego
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math/rand"
+	"testing"
+)
+
+func TestStego(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	cover := image.NewRGBA(image.Rect(0, 0, 64, 48))
+	for y := 0; y < 48; y++ {
+		for x := 0; x < 64; x++ {
+			cover.Set(x, y, color.RGBA{uint8(x * 4), uint8(y * 5), uint8(random.Intn(256)), 255})
+		}
+	}
+	var coverPNG, coverJPEG bytes.Buffer
+	if err := png.Encode(&coverPNG, cover); err != nil {
+		t.Fatalf("failed to encode cover: %s", err)
+	}
+	if err := jpeg.Encode(&coverJPEG, cover, nil); err != nil {
+		t.Fatalf("failed to encode cover: %s", err)
+	}
+	stego := NewStego()
+	capacity, err := stego.Capacity(coverPNG.Bytes())
+	if err != nil || capacity != 64*48*3/8-headerLength {
+		t.Fatalf("unexpected capacity: %d, %v", capacity, err)
+	}
+	payload := make([]byte, capacity)
+	random.Read(payload)
+
+	for name, cover := range map[string][]byte{"png": coverPNG.Bytes(), "jpeg": coverJPEG.Bytes()} {
+		embedded, err := stego.Embed(cover, payload, []byte("password"))
+		if err != nil {
+			t.Fatalf("failed to embed into %s: %s", name, err)
+		}
+		extracted, err := stego.Extract(embedded, []byte("password"))
+		if err != nil {
+			t.Fatalf("failed to extract from %s: %s", name, err)
+		}
+		if !bytes.Equal(extracted, payload) {
+			t.Fatalf("extracted payload from %s doesn't match", name)
+		}
+		if _, err := stego.Extract(embedded, []byte("wrong password")); err == nil {
+			t.Fatalf("payload has been extracted from %s with a wrong password", name)
+		}
+	}
+
+	embedded, err := stego.Embed(coverPNG.Bytes(), payload[:100], []byte("password"))
+	if err != nil {
+		t.Fatalf("failed to embed: %s", err)
+	}
+	decoded, err := png.Decode(bytes.NewReader(embedded))
+	if err != nil {
+		t.Fatalf("failed to decode embedded image: %s", err)
+	}
+	// every channel differs from the cover by one at most
+	changed := 0
+	for y := 0; y < 48; y++ {
+		for x := 0; x < 64; x++ {
+			r1, g1, b1, _ := cover.At(x, y).RGBA()
+			r2, g2, b2, _ := decoded.At(x, y).RGBA()
+			for _, diff := range []int{int(r1>>8) - int(r2>>8), int(g1>>8) - int(g2>>8), int(b1>>8) - int(b2>>8)} {
+				if diff < -1 || diff > 1 {
+					t.Fatalf("pixel (%d, %d) has been changed by more than one", x, y)
+				}
+				if diff != 0 {
+					changed++
+				}
+			}
+		}
+	}
+	if changed == 0 || changed > (100+headerLength)*8 {
+		t.Fatalf("unexpected number of changed channels: %d", changed)
+	}
+
+	if _, err := stego.Embed(coverPNG.Bytes(), make([]byte, capacity+1), []byte("password")); err == nil {
+		t.Fatalf("payload exceeding the capacity has been embedded")
+	}
+}
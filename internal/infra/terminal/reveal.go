@@ -0,0 +1,128 @@
+package terminal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const (
+	enterAlternateScreen = "\x1b[?1049h"
+	leaveAlternateScreen = "\x1b[?1049l"
+	clearScreen          = "\x1b[H\x1b[2J\x1b[3J"
+)
+
+// hiddenLineMask replaces the lines which aren't revealed in per-line mode
+const hiddenLineMask = "••••••••"
+
+// Reveal shows the lines in the alternate screen buffer of the terminal, so they never reach the scrollback,
+// and clears them after the timeout or a keypress; in per-line mode a single line is revealed at a time
+// and the timeout is restarted on every keypress, zero timeout disables it
+func (t Terminal) Reveal(lines []string, timeout time.Duration, perLine bool) error {
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		return fmt.Errorf("stdout is not a terminal: the secret is shown only in a terminal")
+	}
+	tty, release, err := openTerminal()
+	if err != nil {
+		return err
+	}
+	defer release()
+	fd := int(tty.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("failed to switch the terminal into raw mode: %w", err)
+	}
+	defer term.Restore(fd, state)
+
+	// the screen is cleared on termination signals as well, Ctrl-C is received as a key in raw mode
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(signals)
+	keys := make(chan byte, 16)
+	go func() {
+		input := bufio.NewReader(tty)
+		for {
+			key, err := input.ReadByte()
+			if err != nil {
+				close(keys)
+				return
+			}
+			keys <- key
+		}
+	}()
+
+	fmt.Print(enterAlternateScreen)
+	defer fmt.Print(clearScreen + leaveAlternateScreen)
+	current := 0
+	for {
+		renderLines(os.Stdout, lines, timeout, perLine, current)
+		var expired <-chan time.Time
+		if timeout > 0 {
+			expired = time.After(timeout)
+		}
+		select {
+		case <-expired:
+			return nil
+		case <-signals:
+			return fmt.Errorf("interrupted")
+		case key, ok := <-keys:
+			if !ok || !perLine {
+				return nil
+			}
+			if current, ok = navigate(key, current, len(lines)); !ok {
+				return nil
+			}
+		}
+	}
+}
+
+// navigate returns the line revealed after the key in per-line mode, false hides the secret
+func navigate(key byte, current int, count int) (int, bool) {
+	switch key {
+	case ' ', '\r', '\n':
+		if current == count-1 {
+			return current, false
+		}
+		return current + 1, true
+	case 127, 8: // Backspace
+		if current > 0 {
+			current--
+		}
+		return current, true
+	}
+	return current, false
+}
+
+// renderLines redraws the screen, raw mode requires explicit carriage returns
+func renderLines(output io.Writer, lines []string, timeout time.Duration, perLine bool, current int) {
+	var screen strings.Builder
+	screen.WriteString(clearScreen)
+	switch {
+	case perLine:
+		screen.WriteString("Space or Enter reveals the next line, Backspace the previous one, any other key hides the secret")
+	default:
+		screen.WriteString("Press any key to hide the secret")
+	}
+	if timeout > 0 {
+		fmt.Fprintf(&screen, " (hidden automatically after %s)", timeout)
+	}
+	screen.WriteString("\r\n\r\n")
+	for i, line := range lines {
+		if !perLine {
+			fmt.Fprintf(&screen, "%s\r\n", line)
+			continue
+		}
+		if i != current {
+			line = hiddenLineMask
+		}
+		fmt.Fprintf(&screen, "%4d: %s\r\n", i+1, line)
+	}
+	fmt.Fprint(output, screen.String())
+}
@@ -0,0 +1,129 @@
+package terminal
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// ReceiveSecret prompts user to enter a multi-line secret with hidden input (twice),
+// the input is finished with Ctrl-D
+func (t Terminal) ReceiveSecret() ([]byte, error) {
+	tty, release, err := openTerminal()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	fd := int(tty.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to switch the terminal into raw mode: %w", err)
+	}
+	defer term.Restore(fd, state)
+
+	// both prompts share the reader, so pasted input buffered after the first Ctrl-D isn't lost
+	input := bufio.NewReader(tty)
+	fmt.Print("Please enter the secret, the input is hidden. Press Ctrl-D to finish:\r\n")
+	secret, err := readHiddenLines(input, os.Stdout)
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while reading the secret: %w", err)
+	}
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("the secret can't be empty")
+	}
+	fmt.Print("Please re-enter the secret:\r\n")
+	secretRepeat, err := readHiddenLines(input, os.Stdout)
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while reading the repeated secret: %w", err)
+	}
+	if !bytes.Equal(secret, secretRepeat) {
+		return nil, fmt.Errorf("secrets do not match")
+	}
+	return secret, nil
+}
+
+// ReceiveStdin reads the secret piped into stdin
+func (t Terminal) ReceiveStdin() ([]byte, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, fmt.Errorf("stdin is a terminal: pipe the secret into it or use --prompt")
+	}
+	secret, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while reading stdin: %w", err)
+	}
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("the secret can't be empty")
+	}
+	return secret, nil
+}
+
+// readHiddenLines reads lines without echo from the terminal in raw mode until Ctrl-D,
+// only the number of the line is shown, every line is terminated with a newline
+func readHiddenLines(input *bufio.Reader, output io.Writer) ([]byte, error) {
+	var secret, line []byte
+	var previous byte
+	for lines := 1; ; {
+		fmt.Fprintf(output, "\r\x1b[Kline %d: ", lines)
+		key, err := input.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		crlf := key == '\n' && previous == '\r'
+		previous = key
+		switch {
+		case crlf: // pasted text with CRLF line endings has a single line break
+		case key == 3: // Ctrl-C
+			fmt.Fprint(output, "\r\n")
+			return nil, fmt.Errorf("input has been cancelled")
+		case key == 4: // Ctrl-D
+			if len(line) > 0 {
+				secret = append(append(secret, line...), '\n')
+			}
+			fmt.Fprint(output, "\r\x1b[K")
+			return secret, nil
+		case key == '\r' || key == '\n':
+			secret = append(append(secret, line...), '\n')
+			line = line[:0]
+			lines++
+		case key == 127 || key == 8: // Backspace removes the last character, which may be several bytes long
+			if len(line) > 0 {
+				_, size := utf8.DecodeLastRune(line)
+				line = line[:len(line)-size]
+			}
+		case key == 27: // escape sequences of arrow and function keys are ignored
+			skipEscapeSequence(input)
+		case key < 0x20 && key != '\t':
+			// other control characters are ignored
+		default:
+			line = append(line, key)
+		}
+	}
+}
+
+// readPassword reads a line without echo from the terminal
+func readPassword() ([]byte, error) {
+	tty, release, err := openTerminal()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return term.ReadPassword(int(tty.Fd()))
+}
+
+// openTerminal returns stdin if it's a terminal, otherwise the terminal is opened directly
+// (e.g. the plaintext is piped with --stdin), the returned function releases it
+func openTerminal() (*os.File, func(), error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return os.Stdin, func() {}, nil
+	}
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return nil, nil, fmt.Errorf("no terminal available to read the input from: %w", err)
+	}
+	return tty, func() { tty.Close() }, nil
+}
@@ -3,8 +3,6 @@ package terminal
 import (
 	"bytes"
 	"fmt"
-
-	"golang.org/x/term"
 )
 
 type (
@@ -25,7 +23,7 @@ func NewTerminal(cfg Config) *Terminal {
 // ReceiveEncryptionPwd promts user to enter a password for encryption (twice)
 func (t Terminal) ReceiveEncryptionPwd() ([]byte, error) {
 	fmt.Printf("Please enter your password (min. %d characters): ", t.cfg.MinPwdLength)
-	secret, err := term.ReadPassword(0)
+	secret, err := readPassword()
 	if err != nil {
 		return nil, fmt.Errorf("an error occurred while reading the password: %w", err)
 	}
@@ -36,7 +34,7 @@ func (t Terminal) ReceiveEncryptionPwd() ([]byte, error) {
 		return nil, fmt.Errorf("passwords longer than %d are not supported", t.cfg.MaxPwdLength)
 	}
 	fmt.Print("\nPlease re-enter your password: ")
-	secretRepeat, err := term.ReadPassword(0)
+	secretRepeat, err := readPassword()
 	if err != nil {
 		return nil, fmt.Errorf("an error occurred while reading the repeated password: %w", err)
 	}
@@ -50,7 +48,7 @@ func (t Terminal) ReceiveEncryptionPwd() ([]byte, error) {
 // ReceiveDecryptionPwd promts user to enter a password for decryption
 func (t Terminal) ReceiveDecryptionPwd() ([]byte, error) {
 	fmt.Printf("Please enter your password: ")
-	secret, err := term.ReadPassword(0)
+	secret, err := readPassword()
 	if err != nil {
 		return nil, fmt.Errorf("an error occurred while reading the password: %w", err)
 	}
@@ -0,0 +1,85 @@
+package terminal
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadHiddenLines(t *testing.T) {
+	for input, expected := range map[string]string{
+		"line one\rline two\x04":             "line one\nline two\n",
+		"secret\r\x04":                       "secret\n",
+		"typo\x7f\x7fed\x04":                 "tyed\n",
+		"ünï\x7f\x7fn\x04":                   "ün\n",
+		"a\x1b[Ab\x1bOD\x01c\x04":            "abc\n",
+		"pasted\r\nwith CRLF\r\n\r\nend\x04": "pasted\nwith CRLF\n\nend\n",
+	} {
+		var output bytes.Buffer
+		secret, err := readHiddenLines(bufio.NewReader(strings.NewReader(input)), &output)
+		if err != nil || string(secret) != expected {
+			t.Fatalf("unexpected secret of %q: %q, %v", input, secret, err)
+		}
+		// nothing typed is echoed
+		if bytes.Contains(output.Bytes(), []byte("line one")) || bytes.Contains(output.Bytes(), []byte("secret")) {
+			t.Fatalf("the input has been echoed: %q", output.String())
+		}
+	}
+	if _, err := readHiddenLines(bufio.NewReader(strings.NewReader("abc\x03")), io.Discard); err == nil {
+		t.Fatal("expected Ctrl-C to cancel the input")
+	}
+	if _, err := readHiddenLines(bufio.NewReader(strings.NewReader("abc")), io.Discard); err == nil {
+		t.Fatal("expected an error for the input closed before Ctrl-D")
+	}
+
+	// the secret and its confirmation pasted at once are read by the same reader
+	input := bufio.NewReader(strings.NewReader("first\rsecond\r\x04first\rsecond\r\x04"))
+	secret, err := readHiddenLines(input, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repeat, err := readHiddenLines(input, io.Discard)
+	if err != nil || !bytes.Equal(secret, repeat) {
+		t.Fatalf("the confirmation doesn't match: %q, %q, %v", secret, repeat, err)
+	}
+}
+
+func TestReveal(t *testing.T) {
+	lines := []string{"alpha", "bravo", "charlie"}
+	var output bytes.Buffer
+	renderLines(&output, lines, time.Minute, true, 1)
+	screen := output.String()
+	if !strings.Contains(screen, "   2: bravo\r\n") || strings.Contains(screen, "alpha") || strings.Contains(screen, "charlie") {
+		t.Fatalf("only the current line has to be revealed:\n%s", screen)
+	}
+	if strings.Count(screen, hiddenLineMask) != 2 || !strings.Contains(screen, "(hidden automatically after 1m0s)") {
+		t.Fatalf("unexpected screen:\n%s", screen)
+	}
+	output.Reset()
+	renderLines(&output, lines, 0, false, 0)
+	if !strings.Contains(output.String(), "alpha\r\nbravo\r\ncharlie\r\n") || strings.Contains(output.String(), "hidden automatically") {
+		t.Fatalf("all lines have to be revealed:\n%s", output.String())
+	}
+
+	for _, step := range []struct {
+		key      byte
+		current  int
+		next     int
+		revealed bool
+	}{
+		{' ', 0, 1, true},
+		{'\r', 1, 2, true},
+		{'\r', 2, 2, false}, // the last line is hidden on the next key
+		{127, 2, 1, true},
+		{127, 0, 0, true},
+		{'q', 1, 1, false},
+	} {
+		next, revealed := navigate(step.key, step.current, len(lines))
+		if next != step.next || revealed != step.revealed {
+			t.Fatalf("unexpected navigation by %q from line %d: %d, %v", step.key, step.current, next, revealed)
+		}
+	}
+}
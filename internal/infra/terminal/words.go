@@ -0,0 +1,157 @@
+package terminal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/d347h-eth/aesgcm/internal/infra/wordlist"
+	"golang.org/x/term"
+)
+
+// maxCandidates limits the number of candidates shown for an ambiguous prefix
+const maxCandidates = 6
+
+// hiddenWordMask replaces accepted words in hidden mode
+const hiddenWordMask = "********"
+
+type (
+	// wordPrompt reads BIP39 words key by key: letters which don't continue any word are rejected,
+	// a unique prefix is completed on Space, Tab or Enter
+	wordPrompt struct {
+		input   *bufio.Reader
+		output  io.Writer
+		hidden  bool
+		words   []string
+		current string
+	}
+)
+
+// ReceiveWords prompts user to enter BIP39 words one by one with autocompletion,
+// hidden words aren't echoed, piped input is read as whitespace separated words
+func (t Terminal) ReceiveWords(hidden bool) ([]string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		input, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("an error occurred while reading the words: %w", err)
+		}
+		return strings.Fields(string(input)), nil
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to switch the terminal into raw mode: %w", err)
+	}
+	defer term.Restore(fd, state)
+	fmt.Print("Please enter the words one by one, a word is completed with Space, Tab or Enter as soon as its prefix is unique.\r\n" +
+		"Press Backspace on an empty word to edit the previous one and Enter on an empty word to finish.\r\n")
+	prompt := &wordPrompt{input: bufio.NewReader(os.Stdin), output: os.Stdout, hidden: hidden}
+	return prompt.run()
+}
+
+func (p *wordPrompt) run() ([]string, error) {
+	for {
+		p.redraw()
+		key, err := p.input.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("an error occurred while reading the words: %w", err)
+		}
+		switch {
+		case key == 3: // Ctrl-C
+			fmt.Fprint(p.output, "\r\n")
+			return nil, fmt.Errorf("input has been cancelled")
+		case key == 27: // escape sequences of arrow and function keys are ignored
+			skipEscapeSequence(p.input)
+		case key == 127 || key == 8: // Backspace
+			if p.current != "" {
+				p.current = p.current[:len(p.current)-1]
+			} else if len(p.words) > 0 {
+				p.current = p.words[len(p.words)-1]
+				p.words = p.words[:len(p.words)-1]
+				fmt.Fprint(p.output, "\r\x1b[K\x1b[1A")
+			}
+		case key == '\r' || key == '\n' || key == 4: // Enter, Ctrl-D
+			if p.current != "" {
+				p.accept()
+			} else if len(p.words) > 0 || key == 4 {
+				fmt.Fprint(p.output, "\r\x1b[K")
+				return p.words, nil
+			}
+		case key == ' ' || key == '\t':
+			if p.current != "" {
+				p.accept()
+			}
+		case key >= 'a' && key <= 'z' || key >= 'A' && key <= 'Z':
+			next := p.current + strings.ToLower(string(key))
+			if len(wordlist.BIP39.Complete(next)) == 0 {
+				p.bell()
+				continue
+			}
+			p.current = next
+		default:
+			p.bell()
+		}
+	}
+}
+
+// accept completes the current word if its prefix is unique
+func (p *wordPrompt) accept() {
+	word, err := wordlist.BIP39.Resolve(p.current)
+	if err != nil {
+		p.bell()
+		return
+	}
+	p.words = append(p.words, word)
+	p.current = ""
+	if p.hidden {
+		// a fixed mask doesn't reveal the length of the word
+		word = hiddenWordMask
+	}
+	fmt.Fprintf(p.output, "\r\x1b[K%4d: %s\r\n", len(p.words), word)
+}
+
+// redraw prints the current word with its completion and candidates,
+// the cursor is kept after the typed letters
+func (p *wordPrompt) redraw() {
+	fmt.Fprintf(p.output, "\r\x1b[K%4d: ", len(p.words)+1)
+	if p.hidden {
+		fmt.Fprint(p.output, strings.Repeat("*", len(p.current)))
+		return
+	}
+	fmt.Fprint(p.output, p.current)
+	if p.current == "" {
+		return
+	}
+	candidates := wordlist.BIP39.Complete(p.current)
+	hint := ""
+	switch {
+	case len(candidates) == 1:
+		hint = "\x1b[2m" + candidates[0][len(p.current):] + "\x1b[0m"
+	case len(candidates) > maxCandidates:
+		hint = fmt.Sprintf("  (%s …)", strings.Join(candidates[:maxCandidates], ", "))
+	default:
+		hint = fmt.Sprintf("  (%s)", strings.Join(candidates, ", "))
+	}
+	// save the cursor position, print the hint and restore the position
+	fmt.Fprint(p.output, "\x1b7"+hint+"\x1b8")
+}
+
+// skipEscapeSequence skips the rest of escape sequence sent by arrow and function keys
+func skipEscapeSequence(input *bufio.Reader) {
+	if next, err := input.Peek(1); err != nil || (next[0] != '[' && next[0] != 'O') {
+		return
+	}
+	input.ReadByte()
+	for {
+		key, err := input.ReadByte()
+		if err != nil || key >= 0x40 && key <= 0x7e {
+			return
+		}
+	}
+}
+
+func (p *wordPrompt) bell() {
+	fmt.Fprint(p.output, "\a")
+}
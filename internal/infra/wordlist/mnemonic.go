@@ -0,0 +1,62 @@
+package wordlist
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+)
+
+// maxUnknownWords is the number of unknown words a mnemonic is still recognized with, so typos are reported
+const maxUnknownWords = 2
+
+// LooksLikeMnemonic tells if the words are probably a BIP39 mnemonic, possibly with typos or a missing word
+func (w Wordlist) LooksLikeMnemonic(words []string) bool {
+	if len(words) < 12-1 || len(words) > 24+1 {
+		return false
+	}
+	unknown := 0
+	for _, word := range words {
+		if _, ok := w.index[strings.ToLower(word)]; !ok {
+			unknown++
+		}
+	}
+	return unknown <= maxUnknownWords
+}
+
+// ValidateMnemonic checks the number of words, the words themselves and the checksum of BIP39 mnemonic,
+// the checksum is the first ENT/32 bits of SHA-256 of the entropy
+func (w Wordlist) ValidateMnemonic(words []string) error {
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return fmt.Errorf("12, 15, 18, 21 or 24 words expected, got %d", len(words))
+	}
+	values := make([]int, len(words))
+	for i, word := range words {
+		value, ok := w.index[strings.ToLower(word)]
+		if !ok {
+			resolved, err := w.Resolve(word)
+			if err == nil {
+				// an abbreviated word has to be written in full
+				err = &UnknownWordError{word, []string{resolved}}
+			}
+			return fmt.Errorf("word %d: %w", i+1, err)
+		}
+		values[i] = value
+	}
+
+	checksumBits := len(words) / 3
+	entropy := make([]byte, (len(words)*11-checksumBits)/8)
+	checksum := 0
+	for i := 0; i < len(words)*11; i++ {
+		bit := values[i/11] >> (10 - i%11) & 1
+		if i < len(entropy)*8 {
+			entropy[i/8] |= byte(bit) << (7 - i%8)
+		} else {
+			checksum = checksum<<1 | bit
+		}
+	}
+	hash := sha256.Sum256(entropy)
+	if int(hash[0]>>(8-checksumBits)) != checksum {
+		return fmt.Errorf("checksum mismatch: a word is mistyped, missing or out of order")
+	}
+	return nil
+}
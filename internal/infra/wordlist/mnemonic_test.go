@@ -0,0 +1,50 @@
+package wordlist
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateMnemonic(t *testing.T) {
+	// test vectors of BIP-0039
+	valid := []string{
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		"legal winner thank year wave sausage worth useful legal winner thank yellow",
+		"letter advice cage absurd amount doctor acoustic avoid letter advice cage absurd amount doctor acoustic avoid letter always",
+		"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo vote",
+		"Void Come Effort Suffer Camp Survey Warrior Heavy Shoot Primary Clutch Crush " +
+			"Open Amazing Screen Patrol Group Space Point Ten Exist Slush Involve Unfold",
+	}
+	for _, mnemonic := range valid {
+		words := strings.Fields(mnemonic)
+		if !BIP39.LooksLikeMnemonic(words) {
+			t.Fatalf("mnemonic hasn't been recognized: %s", mnemonic)
+		}
+		if err := BIP39.ValidateMnemonic(words); err != nil {
+			t.Fatalf("valid mnemonic has been rejected: %s: %s", mnemonic, err)
+		}
+	}
+
+	invalid := map[string]string{
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon": "checksum mismatch",
+		"legal winner thank year wave sausage worth useful legal winner yellow thank":                     "checksum mismatch",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about":           "12, 15, 18, 21 or 24 words expected",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandn about":    `word 11: unknown word "abandn", did you mean abandon?`,
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon aban about":      `word 11: unknown word "aban", did you mean abandon?`,
+	}
+	for mnemonic, expected := range invalid {
+		words := strings.Fields(mnemonic)
+		if !BIP39.LooksLikeMnemonic(words) {
+			t.Fatalf("mnemonic with a typo hasn't been recognized: %s", mnemonic)
+		}
+		if err := BIP39.ValidateMnemonic(words); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected %q for %s, got: %v", expected, mnemonic, err)
+		}
+	}
+
+	for _, text := range []string{"hello world", "the quick brown fox jumps over the lazy dog and runs far away"} {
+		if BIP39.LooksLikeMnemonic(strings.Fields(text)) {
+			t.Fatalf("text has been recognized as mnemonic: %s", text)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package wordlist
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39/wordlists"
+)
+
+// maxSuggestions limits the number of words suggested for an unknown word
+const maxSuggestions = 5
+
+type (
+	// Wordlist maps 11-bit values onto words, the words may be abbreviated to a unique prefix,
+	// e.g. the first 4 letters of BIP39 words engraved on a steel plate
+	Wordlist struct {
+		words  []string
+		sorted []string
+		index  map[string]int
+	}
+
+	// UnknownWordError is returned when the word isn't a part of the list,
+	// the words which differ from it by a single letter are suggested
+	UnknownWordError struct {
+		Word        string
+		Suggestions []string
+	}
+
+	// AmbiguousWordError is returned when the prefix matches several words
+	AmbiguousWordError struct {
+		Prefix     string
+		Candidates []string
+	}
+)
+
+// BIP39 is the English wordlist of BIP-0039
+var BIP39 = New(wordlists.English)
+
+// New creates a wordlist, the value of a word is its position in the list
+func New(words []string) *Wordlist {
+	index := make(map[string]int, len(words))
+	for i, word := range words {
+		index[word] = i
+	}
+	sorted := append([]string(nil), words...)
+	sort.Strings(sorted)
+	return &Wordlist{words, sorted, index}
+}
+
+func (e *UnknownWordError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return fmt.Sprintf("unknown word %q", e.Word)
+	}
+	return fmt.Sprintf("unknown word %q, did you mean %s?", e.Word, strings.Join(e.Suggestions, ", "))
+}
+
+func (e *AmbiguousWordError) Error() string {
+	return fmt.Sprintf("ambiguous word prefix %q: %s", e.Prefix, strings.Join(e.Candidates, ", "))
+}
+
+// Len returns the number of words
+func (w Wordlist) Len() int {
+	return len(w.words)
+}
+
+// Word returns the word by its value
+func (w Wordlist) Word(value int) string {
+	return w.words[value]
+}
+
+// Value returns the value of the word
+func (w Wordlist) Value(word string) (int, bool) {
+	value, ok := w.index[word]
+	return value, ok
+}
+
+// Complete returns the words starting with the prefix in alphabetical order
+func (w Wordlist) Complete(prefix string) []string {
+	start := sort.SearchStrings(w.sorted, prefix)
+	end := start
+	for end < len(w.sorted) && strings.HasPrefix(w.sorted[end], prefix) {
+		end++
+	}
+	return w.sorted[start:end]
+}
+
+// Resolve returns the word identified by the input: the word itself or its unique prefix,
+// the input is case-insensitive
+func (w Wordlist) Resolve(input string) (string, error) {
+	input = strings.ToLower(input)
+	if _, ok := w.index[input]; ok {
+		return input, nil
+	}
+	candidates := w.Complete(input)
+	switch {
+	case len(candidates) == 1:
+		return candidates[0], nil
+	case len(candidates) > 1:
+		return "", &AmbiguousWordError{input, candidates}
+	}
+	return "", &UnknownWordError{input, w.suggest(input)}
+}
+
+// suggest finds the words which differ from the input (or its prefix of the same length)
+// by a single substitution, insertion, deletion or transposition
+func (w Wordlist) suggest(input string) []string {
+	var suggestions []string
+	for _, word := range w.sorted {
+		if editedOnce(input, word) || (len(word) > len(input) && editedOnce(input, word[:len(input)])) {
+			suggestions = append(suggestions, word)
+		}
+		if len(suggestions) == maxSuggestions {
+			break
+		}
+	}
+	return suggestions
+}
+
+func editedOnce(a string, b string) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	switch len(b) - len(a) {
+	case 0:
+		differences := 0
+		for i := range a {
+			if a[i] != b[i] {
+				differences++
+			}
+		}
+		if differences == 2 { // transposition of adjacent letters
+			for i := 0; i+1 < len(a); i++ {
+				if a[i] != b[i] {
+					return a[i] == b[i+1] && a[i+1] == b[i] && a[i+2:] == b[i+2:]
+				}
+			}
+		}
+		return differences == 1
+	case 1:
+		for i := range a {
+			if a[i] != b[i] {
+				return a[i:] == b[i+1:]
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -1,6 +1,8 @@
 package codec
 
 import (
+	"encoding/json"
+
 	"github.com/d347h-eth/aesgcm/internal/domain"
 
 	"fmt"
@@ -9,9 +11,10 @@ import (
 type (
 	// Codec is a component responsible for encryption/decryption of data
 	Codec struct {
-		cfg    Config
-		cipher Cipher
-		rnd    RandomnessProvider
+		cfg        Config
+		cipher     Cipher
+		rnd        RandomnessProvider
+		compressor Compressor
 	}
 
 	// Config ...
@@ -20,12 +23,26 @@ type (
 		KeyDerivationIterations int
 		KeyDerivationLength     int
 		NonceLength             int
+		// MetadataEncrypted determines if file metadata is packed inside the ciphertext or kept in the clear header
+		MetadataEncrypted bool
+		// Compression is the algorithm used to compress the plaintext before encryption
+		Compression string
+		// MaxDecompressedSize limits the size of decompressed plaintext
+		MaxDecompressedSize int64
+		// Padding is the scheme used to pad the plaintext before encryption to hide its length
+		Padding string
 	}
 
 	// Cipher ...
 	Cipher interface {
-		Encrypt(password []byte, salt []byte, derivationIter int, derivationLength int, nonce []byte, plaintext []byte) ([]byte, error)
-		Decrypt(password []byte, salt []byte, derivationIter int, derivationLength int, nonce []byte, ciphertext []byte) ([]byte, error)
+		Encrypt(password []byte, salt []byte, derivationIter int, derivationLength int, nonce []byte, plaintext []byte, additionalData []byte) ([]byte, error)
+		Decrypt(password []byte, salt []byte, derivationIter int, derivationLength int, nonce []byte, ciphertext []byte, additionalData []byte) ([]byte, error)
+	}
+
+	// Compressor ...
+	Compressor interface {
+		Compress(algorithm string, data []byte) ([]byte, error)
+		Decompress(algorithm string, data []byte, maxSize int64) ([]byte, error)
 	}
 
 	// RandomnessProvider ...
@@ -34,12 +51,12 @@ type (
 	}
 )
 
-func NewCodec(cfg Config, cipher Cipher, rnd RandomnessProvider) *Codec {
-	return &Codec{cfg, cipher, rnd}
+func NewCodec(cfg Config, cipher Cipher, rnd RandomnessProvider, compressor Compressor) *Codec {
+	return &Codec{cfg, cipher, rnd, compressor}
 }
 
 // Encrypt ...
-func (c Codec) Encrypt(password []byte, plaintext []byte) (*domain.DTO, error) {
+func (c Codec) Encrypt(password []byte, plaintext []byte, metadata *domain.Metadata) (*domain.DTO, error) {
 	// generate randomness
 	salt, err := c.rnd.GetRandomBytes(c.cfg.SaltLength)
 	if err != nil {
@@ -49,6 +66,40 @@ func (c Codec) Encrypt(password []byte, plaintext []byte) (*domain.DTO, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate nonce: %w", err)
 	}
+	// pack metadata either into the header or together with the plaintext
+	header := domain.NewHeader()
+	envelope := domain.Envelope{}
+	if c.cfg.MetadataEncrypted {
+		envelope.Metadata = metadata
+		header.MetadataEncrypted = metadata != nil
+	} else {
+		header.Metadata = metadata
+	}
+	// compress
+	if c.cfg.Compression != "" && c.cfg.Compression != "none" {
+		plaintext, err = c.compressor.Compress(c.cfg.Compression, plaintext)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compress plaintext: %w", err)
+		}
+		envelope.Compression = c.cfg.Compression
+	}
+	packed, err := domain.PackEnvelope(envelope, plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack plaintext: %w", err)
+	}
+	// pad
+	if c.cfg.Padding != "" && c.cfg.Padding != PADDING_NONE {
+		paddedLength, err := ParsePadding(c.cfg.Padding)
+		if err != nil {
+			return nil, err
+		}
+		packed = pad(packed, paddedLength)
+		header.Padding = c.cfg.Padding
+	}
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal header: %w", err)
+	}
 	// encrypt
 	ciphertext, err := c.cipher.Encrypt(
 		password,
@@ -56,26 +107,107 @@ func (c Codec) Encrypt(password []byte, plaintext []byte) (*domain.DTO, error) {
 		c.cfg.KeyDerivationIterations,
 		c.cfg.KeyDerivationLength,
 		nonce,
-		plaintext)
+		packed,
+		headerJSON)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create ciphertext: %w", err)
 	}
 	// pack encrypted data into DTO type
-	dto := domain.NewDTO(salt, c.cfg.KeyDerivationIterations, c.cfg.KeyDerivationLength, nonce, ciphertext)
+	dto := domain.NewDTO(headerJSON, salt, c.cfg.KeyDerivationIterations, c.cfg.KeyDerivationLength, nonce, ciphertext)
 	return dto, nil
 }
 
 // Decrypt ...
-func (c Codec) Decrypt(password []byte, dto *domain.DTO) ([]byte, error) {
+func (c Codec) Decrypt(password []byte, dto *domain.DTO) ([]byte, *domain.Metadata, error) {
+	opened, err := c.Open(password, dto)
+	if err != nil {
+		return nil, nil, err
+	}
+	return opened.Plaintext, opened.Metadata, nil
+}
+
+// Open decrypts the DTO and keeps its header and envelope, so the plaintext can be re-encrypted without deriving the key again
+func (c Codec) Open(password []byte, dto *domain.DTO) (*domain.Opened, error) {
+	header, envelope, plaintext, err := c.open(password, dto)
+	if err != nil {
+		return nil, err
+	}
+	if envelope.Compression != "" {
+		plaintext, err = c.compressor.Decompress(envelope.Compression, plaintext, c.cfg.MaxDecompressedSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress plaintext: %w", err)
+		}
+	}
+	metadata := envelope.Metadata
+	if header != nil && !header.MetadataEncrypted {
+		metadata = header.Metadata
+	}
+	return &domain.Opened{Plaintext: plaintext, Metadata: metadata, Header: header, Envelope: envelope}, nil
+}
+
+// Reencrypt encrypts the edited plaintext of the previous DTO opened by Open with a fresh salt and nonce,
+// key derivation parameters, padding, compression and placement of metadata are kept,
+// the configured key derivation parameters are used only when they're stronger
+func (c Codec) Reencrypt(password []byte, plaintext []byte, metadata *domain.Metadata, previous *domain.DTO, opened *domain.Opened) (*domain.DTO, error) {
+	cfg := c.cfg
+	cfg.SaltLength = maxInt(cfg.SaltLength, len(previous.KeyDerivation.Salt))
+	cfg.KeyDerivationIterations = maxInt(cfg.KeyDerivationIterations, previous.KeyDerivation.Iterations)
+	cfg.KeyDerivationLength = maxInt(cfg.KeyDerivationLength, previous.KeyDerivation.Length)
+	cfg.NonceLength = len(previous.Nonce)
+	cfg.Compression = opened.Envelope.Compression
+	cfg.Padding = PADDING_NONE
+	cfg.MetadataEncrypted = true
+	if opened.Header != nil {
+		cfg.Padding = opened.Header.Padding
+		cfg.MetadataEncrypted = opened.Header.MetadataEncrypted
+	}
+	return NewCodec(cfg, c.cipher, c.rnd, c.compressor).Encrypt(password, plaintext, metadata)
+}
+
+// open decrypts the ciphertext, removes the padding and unpacks the envelope,
+// legacy DTO without header contains bare plaintext
+func (c Codec) open(password []byte, dto *domain.DTO) (*domain.Header, domain.Envelope, []byte, error) {
+	var header *domain.Header
+	if len(dto.Header) > 0 {
+		var err error
+		header, err = domain.ParseHeader(dto.Header)
+		if err != nil {
+			return nil, domain.Envelope{}, nil, err
+		}
+	}
 	plaintext, err := c.cipher.Decrypt(
 		password,
 		dto.KeyDerivation.Salt,
 		dto.KeyDerivation.Iterations,
 		dto.KeyDerivation.Length,
 		dto.Nonce,
-		dto.Ciphertext)
+		dto.Ciphertext,
+		dto.Header)
 	if err != nil {
-		return nil, fmt.Errorf("failed to perform decryption: %w", err)
+		return nil, domain.Envelope{}, nil, fmt.Errorf("failed to perform decryption: %w", err)
+	}
+	if header == nil {
+		return nil, domain.Envelope{}, plaintext, nil
+	}
+	if header.Padding != "" {
+		if _, err := ParsePadding(header.Padding); err != nil {
+			return nil, domain.Envelope{}, nil, err
+		}
+		plaintext, err = unpad(plaintext)
+		if err != nil {
+			return nil, domain.Envelope{}, nil, fmt.Errorf("failed to unpad plaintext: %w", err)
+		}
+	}
+	envelope, plaintext, err := domain.UnpackEnvelope(plaintext)
+	if err != nil {
+		return nil, domain.Envelope{}, nil, fmt.Errorf("failed to unpack plaintext: %w", err)
+	}
+	return header, envelope, plaintext, nil
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
 	}
-	return plaintext, nil
+	return b
 }
@@ -3,8 +3,12 @@ package codec
 import (
 	"bytes"
 	"testing"
+	"time"
+
+	"github.com/d347h-eth/aesgcm/internal/domain"
 
 	"github.com/d347h-eth/aesgcm/internal/infra/aesgcm"
+	"github.com/d347h-eth/aesgcm/internal/infra/compression"
 	"github.com/d347h-eth/aesgcm/internal/infra/randomness"
 )
 
@@ -17,7 +21,7 @@ func TestCodec(t *testing.T) {
 		KeyDerivationIterations: 1000000,
 		KeyDerivationLength:     32,
 	}
-	codec := NewCodec(cfg, aesgcm, osRandomness)
+	codec := NewCodec(cfg, aesgcm, osRandomness, compression.NewCompression())
 	var testInput = struct {
 		pwd       []byte
 		plaintext []byte
@@ -25,11 +29,11 @@ func TestCodec(t *testing.T) {
 		[]byte("testpassword"),
 		[]byte("secretplaintext"),
 	}
-	dto, err := codec.Encrypt(testInput.pwd, testInput.plaintext)
+	dto, err := codec.Encrypt(testInput.pwd, testInput.plaintext, nil)
 	if err != nil {
 		t.Fatalf("failed encryption: %s", err)
 	}
-	plaintext, err := codec.Decrypt(testInput.pwd, dto)
+	plaintext, _, err := codec.Decrypt(testInput.pwd, dto)
 	if err != nil {
 		t.Fatalf("failed decryption: %s", err)
 	}
@@ -41,3 +45,185 @@ func TestCodec(t *testing.T) {
 		)
 	}
 }
+
+func TestCodecMetadata(t *testing.T) {
+	aesgcm := aesgcm.NewAESGCM()
+	osRandomness := randomness.NewOSRandomness()
+	metadata := &domain.Metadata{
+		Name:    "secret.txt",
+		Mode:    0640,
+		ModTime: time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC),
+	}
+	for _, encrypted := range []bool{false, true} {
+		cfg := Config{
+			SaltLength:              16,
+			NonceLength:             12,
+			KeyDerivationIterations: 1000,
+			KeyDerivationLength:     32,
+			MetadataEncrypted:       encrypted,
+		}
+		codec := NewCodec(cfg, aesgcm, osRandomness, compression.NewCompression())
+		dto, err := codec.Encrypt([]byte("testpassword"), []byte("secretplaintext"), metadata)
+		if err != nil {
+			t.Fatalf("failed encryption: %s", err)
+		}
+		if leaked := bytes.Contains(dto.Header, []byte(metadata.Name)); leaked == encrypted {
+			t.Fatalf("unexpected metadata visibility in header (encrypted: %t): %s", encrypted, dto.Header)
+		}
+		_, decrypted, err := codec.Decrypt([]byte("testpassword"), dto)
+		if err != nil {
+			t.Fatalf("failed decryption: %s", err)
+		}
+		if decrypted == nil || decrypted.Name != metadata.Name || decrypted.Mode != metadata.Mode ||
+			!decrypted.ModTime.Equal(metadata.ModTime) {
+			t.Fatalf("metadata doesn't match: got %+v, want %+v", decrypted, metadata)
+		}
+		// the header is authenticated, so tampering with it must be detected
+		dto.Header = bytes.Replace(dto.Header, []byte(`"version":1`), []byte(`"version":1 `), 1)
+		if _, _, err := codec.Decrypt([]byte("testpassword"), dto); err == nil {
+			t.Fatalf("tampered header hasn't been detected")
+		}
+	}
+}
+
+func TestCodecCompression(t *testing.T) {
+	aesgcm := aesgcm.NewAESGCM()
+	osRandomness := randomness.NewOSRandomness()
+	plaintext := bytes.Repeat([]byte(`{"key":"value"},`), 1000)
+	for _, algorithm := range []string{"none", "gzip", "zstd"} {
+		cfg := Config{
+			SaltLength:              16,
+			NonceLength:             12,
+			KeyDerivationIterations: 1000,
+			KeyDerivationLength:     32,
+			Compression:             algorithm,
+			MaxDecompressedSize:     int64(len(plaintext)),
+		}
+		codec := NewCodec(cfg, aesgcm, osRandomness, compression.NewCompression())
+		dto, err := codec.Encrypt([]byte("testpassword"), plaintext, nil)
+		if err != nil {
+			t.Fatalf("failed encryption with %s: %s", algorithm, err)
+		}
+		if algorithm != "none" && len(dto.Ciphertext) >= len(plaintext) {
+			t.Fatalf("plaintext hasn't been compressed with %s", algorithm)
+		}
+		decrypted, _, err := codec.Decrypt([]byte("testpassword"), dto)
+		if err != nil {
+			t.Fatalf("failed decryption with %s: %s", algorithm, err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Fatalf("decrypted plaintext doesn't match with %s", algorithm)
+		}
+	}
+}
+
+func TestCodecPadding(t *testing.T) {
+	aesgcm := aesgcm.NewAESGCM()
+	osRandomness := randomness.NewOSRandomness()
+	short := bytes.Repeat([]byte("word "), 12)
+	long := bytes.Repeat([]byte("word "), 24)
+	for _, scheme := range []string{"padme", "block:256"} {
+		cfg := Config{
+			SaltLength:              16,
+			NonceLength:             12,
+			KeyDerivationIterations: 1000,
+			KeyDerivationLength:     32,
+			Padding:                 scheme,
+		}
+		codec := NewCodec(cfg, aesgcm, osRandomness, compression.NewCompression())
+		var lengths []int
+		for _, plaintext := range [][]byte{short, long} {
+			dto, err := codec.Encrypt([]byte("testpassword"), plaintext, nil)
+			if err != nil {
+				t.Fatalf("failed encryption with %s: %s", scheme, err)
+			}
+			decrypted, _, err := codec.Decrypt([]byte("testpassword"), dto)
+			if err != nil {
+				t.Fatalf("failed decryption with %s: %s", scheme, err)
+			}
+			if !bytes.Equal(decrypted, plaintext) {
+				t.Fatalf("decrypted plaintext doesn't match with %s", scheme)
+			}
+			lengths = append(lengths, len(dto.Ciphertext))
+		}
+		if scheme == "block:256" && lengths[0] != lengths[1] {
+			t.Fatalf("ciphertext lengths differ with %s: %v", scheme, lengths)
+		}
+	}
+}
+
+func TestCodecReencrypt(t *testing.T) {
+	aesgcm := aesgcm.NewAESGCM()
+	osRandomness := randomness.NewOSRandomness()
+	cfg := Config{
+		SaltLength:              32,
+		NonceLength:             12,
+		KeyDerivationIterations: 2000,
+		KeyDerivationLength:     32,
+		Compression:             "gzip",
+		MaxDecompressedSize:     1 << 20,
+		Padding:                 "block:64",
+		MetadataEncrypted:       true,
+	}
+	password := []byte("testpassword")
+	metadata := &domain.Metadata{Name: "secret.txt", Mode: 0600}
+	previous, err := NewCodec(cfg, aesgcm, osRandomness, compression.NewCompression()).Encrypt(password, []byte("old secret"), metadata)
+	if err != nil {
+		t.Fatalf("failed encryption: %s", err)
+	}
+
+	// unset parameters are taken from the previous DTO, stronger ones are upgraded
+	codec := NewCodec(Config{KeyDerivationIterations: 3000, MaxDecompressedSize: 1 << 20}, aesgcm, osRandomness, compression.NewCompression())
+	opened, err := codec.Open(password, previous)
+	if err != nil || string(opened.Plaintext) != "old secret" {
+		t.Fatalf("failed to open previous DTO: %v", err)
+	}
+	dto, err := codec.Reencrypt(password, []byte("new secret"), metadata, previous, opened)
+	if err != nil {
+		t.Fatalf("failed re-encryption: %s", err)
+	}
+	if bytes.Equal(dto.KeyDerivation.Salt, previous.KeyDerivation.Salt) || bytes.Equal(dto.Nonce, previous.Nonce) {
+		t.Fatalf("salt and nonce haven't been regenerated")
+	}
+	if len(dto.KeyDerivation.Salt) != 32 || len(dto.Nonce) != 12 || dto.KeyDerivation.Iterations != 3000 || dto.KeyDerivation.Length != 32 {
+		t.Fatalf("unexpected key derivation parameters: %+v, nonce %d", dto.KeyDerivation, len(dto.Nonce))
+	}
+	header, err := domain.ParseHeader(dto.Header)
+	if err != nil || header.Padding != "block:64" || !header.MetadataEncrypted {
+		t.Fatalf("unexpected header: %+v, %v", header, err)
+	}
+	decrypted, decryptedMetadata, err := codec.Decrypt(password, dto)
+	if err != nil || string(decrypted) != "new secret" || decryptedMetadata.Name != "secret.txt" {
+		t.Fatalf("unexpected re-encrypted plaintext: %q, %v", decrypted, err)
+	}
+	if _, err := codec.Open([]byte("wrongpassword"), previous); err == nil {
+		t.Fatalf("expected opening with wrong password to fail")
+	}
+
+	// metadata kept in the clear header stays there
+	cfg.MetadataEncrypted = false
+	previous, err = NewCodec(cfg, aesgcm, osRandomness, compression.NewCompression()).Encrypt(password, []byte("old secret"), metadata)
+	if err != nil {
+		t.Fatalf("failed encryption: %s", err)
+	}
+	opened, err = codec.Open(password, previous)
+	if err != nil {
+		t.Fatalf("failed to open previous DTO: %s", err)
+	}
+	dto, err = codec.Reencrypt(password, []byte("new secret"), metadata, previous, opened)
+	if err != nil {
+		t.Fatalf("failed re-encryption: %s", err)
+	}
+	header, err = domain.ParseHeader(dto.Header)
+	if err != nil || header.MetadataEncrypted || header.Metadata == nil || header.Metadata.Name != "secret.txt" {
+		t.Fatalf("unexpected header: %+v, %v", header, err)
+	}
+}
+
+func TestPadmeLength(t *testing.T) {
+	for length, want := range map[int]int{1: 1, 9: 10, 100: 104, 1000: 1024, 4097: 4352} {
+		if got := padmeLength(length); got != want {
+			t.Fatalf("unexpected padded length of %d: got %d, want %d", length, got, want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package codec
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// PADDING_NONE disables padding
+const PADDING_NONE = "none"
+
+// PADDING_PADME selects PADMÉ padding, which limits the leak of plaintext length to O(log log L) bits
+// with at most 12% overhead
+const PADDING_PADME = "padme"
+
+// PADDING_BLOCK_PREFIX selects padding to a multiple of the specified block size, e.g. "block:4096"
+const PADDING_BLOCK_PREFIX = "block:"
+
+// ParsePadding validates the padding scheme and returns a function computing padded length
+func ParsePadding(scheme string) (func(length int) int, error) {
+	switch {
+	case scheme == PADDING_PADME:
+		return padmeLength, nil
+	case strings.HasPrefix(scheme, PADDING_BLOCK_PREFIX):
+		blockSize, err := strconv.Atoi(strings.TrimPrefix(scheme, PADDING_BLOCK_PREFIX))
+		if err != nil || blockSize <= 0 {
+			return nil, fmt.Errorf("invalid padding block size: %q", scheme)
+		}
+		return func(length int) int {
+			return (length + blockSize - 1) / blockSize * blockSize
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported padding scheme: %q", scheme)
+	}
+}
+
+// padmeLength rounds the length up so that only its top O(log log L) bits are significant
+func padmeLength(length int) int {
+	if length < 2 {
+		return length
+	}
+	e := bits.Len(uint(length)) - 1 // floor(log2 L)
+	s := bits.Len(uint(e))          // floor(log2 E) + 1
+	mask := (1 << (e - s)) - 1      // low bits to be cleared
+	return (length + mask) &^ mask
+}
+
+// pad appends ISO/IEC 7816-4 padding (0x80 followed by zeros) up to the length chosen by the scheme
+func pad(data []byte, paddedLength func(length int) int) []byte {
+	length := paddedLength(len(data) + 1)
+	padded := make([]byte, length)
+	copy(padded, data)
+	padded[len(data)] = 0x80
+	return padded
+}
+
+// unpad strips ISO/IEC 7816-4 padding
+func unpad(data []byte) ([]byte, error) {
+	for i := len(data) - 1; i >= 0; i-- {
+		switch data[i] {
+		case 0x00:
+			continue
+		case 0x80:
+			return data[:i], nil
+		default:
+			return nil, fmt.Errorf("invalid padding")
+		}
+	}
+	return nil, fmt.Errorf("invalid padding")
+}
@@ -0,0 +1,135 @@
+package dotenv
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/d347h-eth/aesgcm/internal/domain"
+)
+
+type (
+	// Dotenv is a component responsible for parsing of dotenv files:
+	// NAME=VALUE lines with optional "export" prefix, comments, single-quoted literal values
+	// and double-quoted values with escape sequences, quoted values may span several lines;
+	// variables aren't expanded
+	Dotenv struct{}
+)
+
+// NewDotenv ...
+func NewDotenv() *Dotenv {
+	return &Dotenv{}
+}
+
+// Parse parses the variables in the order of their definition, a redefined variable takes the last value
+func (d Dotenv) Parse(data []byte) ([]domain.EnvVar, error) {
+	text := strings.TrimPrefix(strings.ReplaceAll(string(data), "\r\n", "\n"), "\ufeff")
+	lines := strings.Split(text, "\n")
+	var vars []domain.EnvVar
+	indexes := map[string]int{}
+	for i := 0; i < len(lines); i++ {
+		number := i + 1
+		line := strings.TrimLeft(lines[i], " \t")
+		if trimmed := strings.TrimSpace(line); trimmed == "" || trimmed[0] == '#' {
+			continue
+		}
+		if rest := strings.TrimPrefix(line, "export"); rest != line && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			line = strings.TrimLeft(rest, " \t")
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected NAME=VALUE", number)
+		}
+		name = strings.TrimSpace(name)
+		if !validName(name) {
+			return nil, fmt.Errorf("line %d: invalid variable name %q", number, name)
+		}
+		value = strings.TrimLeft(value, " \t")
+		if value != "" && (value[0] == '\'' || value[0] == '"') {
+			quote := value[0]
+			body := value[1:]
+			end := closingQuote(body, quote)
+			for end < 0 {
+				if i++; i == len(lines) {
+					return nil, fmt.Errorf("line %d: unterminated quoted value of %q", number, name)
+				}
+				body += "\n" + lines[i]
+				end = closingQuote(body, quote)
+			}
+			if rest := strings.TrimSpace(body[end+1:]); rest != "" && rest[0] != '#' {
+				return nil, fmt.Errorf("line %d: unexpected characters after quoted value of %q", i+1, name)
+			}
+			value = body[:end]
+			if quote == '"' {
+				value = unescape(value)
+			}
+		} else {
+			// an inline comment has to be preceded by whitespace, so values may contain '#'
+			for index := 0; index < len(value); index++ {
+				if value[index] == '#' && (index == 0 || value[index-1] == ' ' || value[index-1] == '\t') {
+					value = value[:index]
+					break
+				}
+			}
+			value = strings.TrimSpace(value)
+		}
+		if index, ok := indexes[name]; ok {
+			vars[index].Value = value
+			continue
+		}
+		indexes[name] = len(vars)
+		vars = append(vars, domain.EnvVar{Name: name, Value: value})
+	}
+	return vars, nil
+}
+
+// validName accepts the portable names of environment variables
+func validName(name string) bool {
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		return false
+	}
+	for _, r := range name {
+		if !(r == '_' || r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// closingQuote returns the index of the closing quote or -1, backslash escapes the quote inside double quotes
+func closingQuote(body string, quote byte) int {
+	for i := 0; i < len(body); i++ {
+		switch {
+		case body[i] == '\\' && quote == '"':
+			i++
+		case body[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// unescape replaces escape sequences of double-quoted values, unknown sequences are kept as is
+func unescape(value string) string {
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i == len(value)-1 {
+			builder.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 'n':
+			builder.WriteByte('\n')
+		case 'r':
+			builder.WriteByte('\r')
+		case 't':
+			builder.WriteByte('\t')
+		case '\\', '"', '$':
+			builder.WriteByte(value[i])
+		default:
+			builder.WriteByte('\\')
+			builder.WriteByte(value[i])
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,51 @@
+package dotenv
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/d347h-eth/aesgcm/internal/domain"
+)
+
+func TestParse(t *testing.T) {
+	input := "\ufeff# database\r\n" +
+		"DB_HOST=localhost # inline comment\n" +
+		"export DB_PASSWORD='p@ss#word $HOME'\n" +
+		"\n" +
+		"  TOKEN = abc#def\n" +
+		"GREETING=\"hello\\n\\\"world\\\" \\$USER\"\n" +
+		"CERT=\"-----BEGIN-----\n" +
+		"MIIB\n" +
+		"-----END-----\"\n" +
+		"EMPTY=\n" +
+		"DB_HOST=db.internal\n"
+	want := []domain.EnvVar{
+		{Name: "DB_HOST", Value: "db.internal"},
+		{Name: "DB_PASSWORD", Value: "p@ss#word $HOME"},
+		{Name: "TOKEN", Value: "abc#def"},
+		{Name: "GREETING", Value: "hello\n\"world\" $USER"},
+		{Name: "CERT", Value: "-----BEGIN-----\nMIIB\n-----END-----"},
+		{Name: "EMPTY", Value: ""},
+	}
+	vars, err := NewDotenv().Parse([]byte(input))
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	if !reflect.DeepEqual(vars, want) {
+		t.Fatalf("unexpected variables:\ngot  %q\nwant %q", vars, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for input, want := range map[string]string{
+		"A=1\nINVALID LINE\n":       "line 2: expected NAME=VALUE",
+		"A=1\nexport\n":             "line 2: expected NAME=VALUE",
+		"1ABC=value":                `line 1: invalid variable name "1ABC"`,
+		"A=1\nKEY=\"unterminated\n": `line 2: unterminated quoted value of "KEY"`,
+		"KEY='value' trailing":      `line 1: unexpected characters after quoted value of "KEY"`,
+	} {
+		if _, err := NewDotenv().Parse([]byte(input)); err == nil || err.Error() != want {
+			t.Fatalf("unexpected error of %q: got %v, want %q", input, err, want)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package eip2335
+
+import (
+	"crypto/aes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/d347h-eth/aesgcm/internal/domain"
+	"github.com/d347h-eth/aesgcm/internal/usecase/kdf"
+	bls12381 "github.com/kilic/bls12-381"
+	"golang.org/x/text/unicode/norm"
+)
+
+// VERSION is the version of EIP-2335 keystore format
+const VERSION = 4
+
+// KDF_SCRYPT is scrypt key derivation function
+const KDF_SCRYPT = kdf.SCRYPT
+
+// KDF_PBKDF2 is PBKDF2 key derivation function with HMAC-SHA256
+const KDF_PBKDF2 = kdf.PBKDF2
+
+// KDFs lists all supported key derivation functions
+var KDFs = []string{KDF_SCRYPT, KDF_PBKDF2}
+
+const (
+	cipherName   = "aes-128-ctr"
+	checksumName = "sha256"
+	saltLength   = 32
+	pubkeyLength = 48
+)
+
+// curveOrder is the order r of BLS12-381 curve, secret keys are scalars below it
+var curveOrder, _ = new(big.Int).SetString("73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001", 16)
+
+type (
+	// Keystore is a component responsible for encryption and decryption of BLS12-381 validator keys
+	// in EIP-2335 keystore format, every crypto step is described by a module of function, params and message
+	Keystore struct {
+		cfg Config
+		rnd RandomnessProvider
+	}
+
+	// Config ...
+	Config struct {
+		// KDF is the key derivation function (scrypt, pbkdf2)
+		KDF string
+		// ScryptN is CPU/memory cost of scrypt
+		ScryptN int
+		// PBKDF2Iterations is the amount of PBKDF2 iterations
+		PBKDF2Iterations int
+	}
+
+	// RandomnessProvider ...
+	RandomnessProvider interface {
+		GetRandomBytes(length int) ([]byte, error)
+	}
+
+	keystoreJSON struct {
+		Crypto      cryptoJSON `json:"crypto"`
+		Description string     `json:"description"`
+		Pubkey      string     `json:"pubkey"`
+		Path        string     `json:"path"`
+		UUID        string     `json:"uuid"`
+		Version     int        `json:"version"`
+	}
+
+	cryptoJSON struct {
+		KDF      moduleJSON `json:"kdf"`
+		Checksum moduleJSON `json:"checksum"`
+		Cipher   moduleJSON `json:"cipher"`
+	}
+
+	moduleJSON struct {
+		Function string     `json:"function"`
+		Params   paramsJSON `json:"params"`
+		Message  string     `json:"message"`
+	}
+
+	// paramsJSON holds parameters of all modules, only the relevant ones are written
+	paramsJSON struct {
+		DKLen int    `json:"dklen,omitempty"`
+		N     int    `json:"n,omitempty"`
+		R     int    `json:"r,omitempty"`
+		P     int    `json:"p,omitempty"`
+		C     int    `json:"c,omitempty"`
+		PRF   string `json:"prf,omitempty"`
+		Salt  string `json:"salt,omitempty"`
+		IV    string `json:"iv,omitempty"`
+	}
+)
+
+// NewKeystore ...
+func NewKeystore(cfg Config, rnd RandomnessProvider) *Keystore {
+	return &Keystore{cfg, rnd}
+}
+
+// Encrypt encrypts the validator key into a keystore file
+func (k Keystore) Encrypt(key *domain.ValidatorKey, password []byte) ([]byte, error) {
+	if err := validateSecret(key.Secret); err != nil {
+		return nil, err
+	}
+	// the given public key is only checked, the written one is always derived from the secret
+	pubkey := derivePubkey(key.Secret)
+	if err := matchPubkey(key.Pubkey, pubkey); err != nil {
+		return nil, err
+	}
+	salt, err := k.rnd.GetRandomBytes(saltLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	iv, err := k.rnd.GetRandomBytes(aes.BlockSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate IV: %w", err)
+	}
+	uuid := key.UUID
+	if uuid == "" {
+		id, err := k.rnd.GetRandomBytes(16)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate UUID: %w", err)
+		}
+		// random UUID (version 4, RFC 4122 variant)
+		id[6] = id[6]&0x0f | 0x40
+		id[8] = id[8]&0x3f | 0x80
+		uuid = fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:])
+	}
+
+	params, err := kdf.NewParams(k.cfg.KDF, k.cfg.ScryptN, k.cfg.PBKDF2Iterations, salt)
+	if err != nil {
+		return nil, err
+	}
+	derivedKey, err := kdf.DeriveKey(NormalizePassword(password), *params)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := kdf.AESCTR(derivedKey[:16], iv, key.Secret)
+	if err != nil {
+		return nil, err
+	}
+	keystore := keystoreJSON{
+		Crypto: cryptoJSON{
+			KDF: moduleJSON{
+				Function: params.Function,
+				Params: paramsJSON{
+					DKLen: params.DKLen,
+					N:     params.N,
+					R:     params.R,
+					P:     params.P,
+					C:     params.C,
+					PRF:   params.PRF,
+					Salt:  hex.EncodeToString(params.Salt),
+				},
+			},
+			Checksum: moduleJSON{Function: checksumName, Message: hex.EncodeToString(checksum(derivedKey, ciphertext))},
+			Cipher: moduleJSON{
+				Function: cipherName,
+				Params:   paramsJSON{IV: hex.EncodeToString(iv)},
+				Message:  hex.EncodeToString(ciphertext),
+			},
+		},
+		Description: key.Description,
+		Pubkey:      pubkey,
+		Path:        key.Path,
+		UUID:        uuid,
+		Version:     VERSION,
+	}
+	return json.MarshalIndent(keystore, "", "  ")
+}
+
+// Decrypt verifies the checksum module of the keystore file and only then decrypts the secret
+func (k Keystore) Decrypt(data []byte, password []byte) (*domain.ValidatorKey, error) {
+	keystore := &keystoreJSON{}
+	if err := json.Unmarshal(data, keystore); err != nil {
+		return nil, fmt.Errorf("failed to parse keystore: %w", err)
+	}
+	if keystore.Version != VERSION {
+		return nil, fmt.Errorf("unsupported keystore version: %d", keystore.Version)
+	}
+	if keystore.Crypto.Checksum.Function != checksumName {
+		return nil, fmt.Errorf("unsupported checksum function: %q", keystore.Crypto.Checksum.Function)
+	}
+	if keystore.Crypto.Cipher.Function != cipherName {
+		return nil, fmt.Errorf("unsupported cipher function: %q", keystore.Crypto.Cipher.Function)
+	}
+	ciphertext, err := hex.DecodeString(keystore.Crypto.Cipher.Message)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cipher message: %w", err)
+	}
+	iv, err := hex.DecodeString(keystore.Crypto.Cipher.Params.IV)
+	if err != nil || len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("invalid cipher IV: %q", keystore.Crypto.Cipher.Params.IV)
+	}
+	expectedChecksum, err := hex.DecodeString(keystore.Crypto.Checksum.Message)
+	if err != nil {
+		return nil, fmt.Errorf("invalid checksum message: %w", err)
+	}
+	params := keystore.Crypto.KDF.Params
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid KDF salt: %w", err)
+	}
+	derivedKey, err := kdf.DeriveKey(NormalizePassword(password), kdf.Params{
+		Function: keystore.Crypto.KDF.Function,
+		DKLen:    params.DKLen,
+		Salt:     salt,
+		N:        params.N,
+		R:        params.R,
+		P:        params.P,
+		C:        params.C,
+		PRF:      params.PRF,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(checksum(derivedKey, ciphertext), expectedChecksum) != 1 {
+		return nil, fmt.Errorf("checksum mismatch: the password is wrong or the keystore has been damaged")
+	}
+	secret, err := kdf.AESCTR(derivedKey[:16], iv, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateSecret(secret); err != nil {
+		return nil, err
+	}
+	pubkey := derivePubkey(secret)
+	if err := matchPubkey(keystore.Pubkey, pubkey); err != nil {
+		return nil, fmt.Errorf("keystore: %w", err)
+	}
+	return &domain.ValidatorKey{
+		Secret:      secret,
+		Pubkey:      pubkey,
+		Path:        keystore.Path,
+		Description: keystore.Description,
+		UUID:        keystore.UUID,
+	}, nil
+}
+
+// NormalizePassword converts the password into NFKD form and strips C0, C1 and Delete control characters
+func NormalizePassword(password []byte) []byte {
+	var output strings.Builder
+	for _, r := range norm.NFKD.String(string(password)) {
+		if r <= 0x1f || r >= 0x7f && r <= 0x9f {
+			continue
+		}
+		output.WriteRune(r)
+	}
+	return []byte(output.String())
+}
+
+// validateSecret checks if the secret is a valid BLS12-381 secret key
+func validateSecret(secret []byte) error {
+	if len(secret) != 32 {
+		return fmt.Errorf("invalid BLS secret key length: %d bytes", len(secret))
+	}
+	if scalar := new(big.Int).SetBytes(secret); scalar.Sign() == 0 || scalar.Cmp(curveOrder) >= 0 {
+		return fmt.Errorf("invalid BLS secret key: out of the curve order")
+	}
+	return nil
+}
+
+// derivePubkey returns the hex encoded compressed BLS12-381 public key of the valid secret key,
+// the public key is the G1 generator multiplied by the secret scalar
+func derivePubkey(secret []byte) string {
+	g1 := bls12381.NewG1()
+	point := g1.MulScalarBig(g1.New(), g1.One(), new(big.Int).SetBytes(secret))
+	return hex.EncodeToString(g1.ToCompressed(point))
+}
+
+// matchPubkey checks the hex encoded public key, optionally prefixed with 0x, against the derived one,
+// an empty public key matches any
+func matchPubkey(pubkey string, derived string) error {
+	trimmed := strings.ToLower(strings.TrimPrefix(pubkey, "0x"))
+	if trimmed == "" {
+		return nil
+	}
+	if decoded, err := hex.DecodeString(trimmed); err != nil || len(decoded) != pubkeyLength {
+		return fmt.Errorf("invalid BLS public key: %q", pubkey)
+	}
+	if trimmed != derived {
+		return fmt.Errorf("the public key %s doesn't match the secret key, expected %s", pubkey, derived)
+	}
+	return nil
+}
+
+// checksum is SHA-256 of the second half of the derived key and the cipher message
+func checksum(derivedKey []byte, ciphertext []byte) []byte {
+	hash := sha256.Sum256(append(append([]byte(nil), derivedKey[16:32]...), ciphertext...))
+	return hash[:]
+}
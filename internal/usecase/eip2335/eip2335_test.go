@@ -0,0 +1,168 @@
+package eip2335
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/d347h-eth/aesgcm/internal/domain"
+	"github.com/d347h-eth/aesgcm/internal/infra/randomness"
+)
+
+// test vectors of EIP-2335
+const (
+	testPassword = "𝔱𝔢𝔰𝔱𝔭𝔞𝔰𝔰𝔴𝔬𝔯𝔡🔑"
+	testSecret   = "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
+	testPubkey   = "9612d7a727c9d0a22e185a1c768478dfe919cada9266988cb32359c11f2b7b27f4ae4040902382ae2910c15e2b420d07"
+)
+
+var testVectors = map[string]string{
+	KDF_SCRYPT: `{
+		"crypto": {
+			"kdf": {
+				"function": "scrypt",
+				"params": {
+					"dklen": 32,
+					"n": 262144,
+					"p": 1,
+					"r": 8,
+					"salt": "d4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3"
+				},
+				"message": ""
+			},
+			"checksum": {
+				"function": "sha256",
+				"params": {},
+				"message": "d2217fe5f3e9a1e34581ef8a78f7c9928e436d36dacc5e846690a5581e8ea484"
+			},
+			"cipher": {
+				"function": "aes-128-ctr",
+				"params": {
+					"iv": "264daa3f303d7259501c93d997d84fe6"
+				},
+				"message": "06ae90d55fe0a6e9c5c3bc5b170827b2e5cce3929ed3f116c2811e6366dfe20f"
+			}
+		},
+		"description": "This is a test keystore that uses scrypt to secure the secret.",
+		"pubkey": "9612d7a727c9d0a22e185a1c768478dfe919cada9266988cb32359c11f2b7b27f4ae4040902382ae2910c15e2b420d07",
+		"path": "m/12381/60/3141592653/589793238",
+		"uuid": "1d85ae20-35c5-4611-98e8-aa14a633906f",
+		"version": 4
+	}`,
+	KDF_PBKDF2: `{
+		"crypto": {
+			"kdf": {
+				"function": "pbkdf2",
+				"params": {
+					"dklen": 32,
+					"c": 262144,
+					"prf": "hmac-sha256",
+					"salt": "d4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3"
+				},
+				"message": ""
+			},
+			"checksum": {
+				"function": "sha256",
+				"params": {},
+				"message": "8a9f5d9912ed7e75ea794bc5a89bca5f193721d30868ade6f73043c6ea6febf1"
+			},
+			"cipher": {
+				"function": "aes-128-ctr",
+				"params": {
+					"iv": "264daa3f303d7259501c93d997d84fe6"
+				},
+				"message": "cee03fde2af33149775b7223e7845e4fb2c8ae1792e5f99fe9ecf474cc8c16ad"
+			}
+		},
+		"description": "This is a test keystore that uses PBKDF2 to secure the secret.",
+		"pubkey": "9612d7a727c9d0a22e185a1c768478dfe919cada9266988cb32359c11f2b7b27f4ae4040902382ae2910c15e2b420d07",
+		"path": "m/12381/60/0/0",
+		"uuid": "64625def-3331-4eea-ab6f-782f3ed16a83",
+		"version": 4
+	}`,
+}
+
+func TestNormalizePassword(t *testing.T) {
+	if normalized := hex.EncodeToString(NormalizePassword([]byte(testPassword))); normalized != "7465737470617373776f7264f09f9491" {
+		t.Fatalf("unexpected normalized password: %s", normalized)
+	}
+	if normalized := string(NormalizePassword([]byte("a\x00b\tc\x7fd\u0085e f"))); normalized != "abcde f" {
+		t.Fatalf("unexpected normalized password: %q", normalized)
+	}
+}
+
+func TestDecrypt(t *testing.T) {
+	expected, _ := hex.DecodeString(testSecret)
+	keystore := NewKeystore(Config{}, randomness.NewOSRandomness())
+	for kdf, vector := range testVectors {
+		key, err := keystore.Decrypt([]byte(vector), []byte(testPassword))
+		if err != nil {
+			t.Fatalf("failed to decrypt %s test vector: %s", kdf, err)
+		}
+		if !bytes.Equal(key.Secret, expected) || key.Pubkey != testPubkey {
+			t.Fatalf("unexpected secret of %s test vector: %x", kdf, key.Secret)
+		}
+		if _, err := keystore.Decrypt([]byte(vector), []byte("testpassword")); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+			t.Fatalf("expected checksum mismatch of %s test vector, got: %v", kdf, err)
+		}
+	}
+}
+
+func TestEncrypt(t *testing.T) {
+	secret, _ := hex.DecodeString(testSecret)
+	for _, kdf := range KDFs {
+		keystore := NewKeystore(Config{KDF: kdf, ScryptN: 1 << 12, PBKDF2Iterations: 1000}, randomness.NewOSRandomness())
+		key := &domain.ValidatorKey{Secret: secret, Pubkey: "0x" + testPubkey, Path: "m/12381/3600/0/0/0"}
+		data, err := keystore.Encrypt(key, []byte(testPassword))
+		if err != nil {
+			t.Fatalf("failed to encrypt with %s: %s", kdf, err)
+		}
+		decrypted, err := keystore.Decrypt(data, []byte("testpassword🔑"))
+		if err != nil {
+			t.Fatalf("failed to decrypt with %s: %s", kdf, err)
+		}
+		if !bytes.Equal(decrypted.Secret, secret) || decrypted.Pubkey != testPubkey || decrypted.Path != key.Path || decrypted.UUID == "" {
+			t.Fatalf("unexpected decrypted key with %s: %+v", kdf, decrypted)
+		}
+	}
+
+	keystore := NewKeystore(Config{KDF: KDF_PBKDF2, PBKDF2Iterations: 1000}, randomness.NewOSRandomness())
+	order, _ := hex.DecodeString("73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001")
+	for _, invalid := range [][]byte{make([]byte, 32), order, secret[1:]} {
+		if _, err := keystore.Encrypt(&domain.ValidatorKey{Secret: invalid}, []byte("password")); err == nil {
+			t.Fatalf("expected error for invalid secret %x", invalid)
+		}
+	}
+	if _, err := keystore.Encrypt(&domain.ValidatorKey{Secret: secret, Pubkey: "abcd"}, []byte("password")); err == nil {
+		t.Fatalf("expected error for invalid public key")
+	}
+	other := "a" + testPubkey[1:]
+	if _, err := keystore.Encrypt(&domain.ValidatorKey{Secret: secret, Pubkey: other}, []byte("password")); err == nil || !strings.Contains(err.Error(), "doesn't match") {
+		t.Fatalf("expected error for mismatching public key, got: %v", err)
+	}
+	data, err := keystore.Encrypt(&domain.ValidatorKey{Secret: secret}, []byte("password"))
+	if err != nil {
+		t.Fatalf("failed to encrypt without public key: %s", err)
+	}
+	if !strings.Contains(string(data), `"pubkey": "`+testPubkey+`"`) {
+		t.Fatalf("expected derived public key in keystore: %s", data)
+	}
+	if _, err := keystore.Decrypt([]byte(strings.Replace(string(data), testPubkey, other, 1)), []byte("password")); err == nil || !strings.Contains(err.Error(), "doesn't match") {
+		t.Fatalf("expected error for keystore with mismatching public key, got: %v", err)
+	}
+}
+
+func TestDerivePubkey(t *testing.T) {
+	secret, _ := hex.DecodeString(testSecret)
+	if pubkey := derivePubkey(secret); pubkey != testPubkey {
+		t.Fatalf("unexpected public key: %s", pubkey)
+	}
+	one := make([]byte, 32)
+	one[31] = 1
+	// compressed generator of G1
+	generator := "97f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb"
+	if pubkey := derivePubkey(one); pubkey != generator {
+		t.Fatalf("unexpected public key of 1: %s", pubkey)
+	}
+}
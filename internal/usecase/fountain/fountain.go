@@ -0,0 +1,266 @@
+package fountain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/d347h-eth/aesgcm/internal/domain"
+)
+
+// FRAME_MAGIC marks a fountain-coded frame
+const FRAME_MAGIC = "AESGCMLT"
+
+// hashLength is the length of the truncated payload hash carried by every frame
+const hashLength = 4
+
+// robust soliton distribution parameters
+const (
+	solitonC     = 0.1
+	solitonDelta = 0.05
+)
+
+type (
+	// Config ...
+	Config struct {
+		// BlockSize is the number of payload bytes carried by a single frame
+		BlockSize int
+		// Frames is the number of generated frames, twice the number of blocks if not positive
+		Frames int
+	}
+
+	// Fountain is a component responsible for Luby transform coding of payloads,
+	// the payload can be reconstructed from any sufficiently large subset of frames
+	Fountain struct {
+		cfg Config
+	}
+
+	// IncompleteError is returned when the frames aren't sufficient to reconstruct the payload
+	IncompleteError struct {
+		Recovered int
+		Total     int
+	}
+
+	// frame is a single encoded symbol, the first frames carry the source blocks as is,
+	// the rest carry XOR of the blocks chosen by the pseudorandom generator seeded with the sequence number
+	frame struct {
+		hash      []byte
+		length    int
+		blockSize int
+		sequence  uint64
+		data      []byte
+	}
+)
+
+func (e *IncompleteError) Error() string {
+	return fmt.Sprintf("recovered %d of %d blocks, more frames are required", e.Recovered, e.Total)
+}
+
+// NewFountain ...
+func NewFountain(cfg Config) *Fountain {
+	return &Fountain{cfg}
+}
+
+// Encode splits the payload into blocks and generates the frames
+func (f Fountain) Encode(payload []byte) ([][]byte, error) {
+	if f.cfg.BlockSize <= 0 {
+		return nil, fmt.Errorf("invalid block size: %d", f.cfg.BlockSize)
+	}
+	if len(payload) == 0 {
+		return nil, fmt.Errorf("empty payload")
+	}
+	blocks := split(payload, f.cfg.BlockSize)
+	if len(blocks) > domain.MAX_FOUNTAIN_BLOCKS {
+		return nil, fmt.Errorf("payload of %d bytes exceeds %d blocks: increase the block size", len(payload), domain.MAX_FOUNTAIN_BLOCKS)
+	}
+	count := f.cfg.Frames
+	if count <= 0 {
+		count = 2 * len(blocks)
+	}
+	hash := payloadHash(payload)
+	cdf := solitonCDF(len(blocks))
+	frames := make([][]byte, 0, count)
+	for sequence := uint64(0); sequence < uint64(count); sequence++ {
+		data := make([]byte, f.cfg.BlockSize)
+		for _, index := range frameBlocks(sequence, len(blocks), cdf) {
+			xor(data, blocks[index])
+		}
+		frames = append(frames, frame{hash, len(payload), f.cfg.BlockSize, sequence, data}.bytes())
+	}
+	return frames, nil
+}
+
+// IsFrame checks if the data looks like a fountain-coded frame
+func (f Fountain) IsFrame(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(FRAME_MAGIC))
+}
+
+// Decode reconstructs the payload from the frames given in any order, duplicates are ignored,
+// IncompleteError is returned if the frames aren't sufficient
+func (f Fountain) Decode(frames [][]byte) ([]byte, error) {
+	var first *frame
+	var peeler *domain.Peeler
+	var cdf []float64
+	seen := make(map[uint64]bool)
+
+	for _, data := range frames {
+		fr, err := parseFrame(data)
+		if err != nil {
+			return nil, err
+		}
+		if first == nil {
+			// the number of blocks is limited before anything is allocated
+			peeler, err = domain.NewPeeler((fr.length + fr.blockSize - 1) / fr.blockSize)
+			if err != nil {
+				return nil, fmt.Errorf("invalid frame %d: %w", fr.sequence, err)
+			}
+			first = fr
+			cdf = solitonCDF(len(peeler.Blocks()))
+		} else if !bytes.Equal(fr.hash, first.hash) || fr.length != first.length || fr.blockSize != first.blockSize {
+			return nil, fmt.Errorf("frames belong to different payloads")
+		}
+		if seen[fr.sequence] || peeler.Complete() {
+			continue
+		}
+		seen[fr.sequence] = true
+		peeler.Add(frameBlocks(fr.sequence, len(peeler.Blocks()), cdf), fr.data)
+	}
+
+	if first == nil {
+		return nil, fmt.Errorf("no frames provided")
+	}
+	blocks := peeler.Blocks()
+	if !peeler.Complete() {
+		return nil, &IncompleteError{Recovered: peeler.Recovered(), Total: len(blocks)}
+	}
+	payload := make([]byte, 0, len(blocks)*first.blockSize)
+	for _, block := range blocks {
+		payload = append(payload, block...)
+	}
+	payload = payload[:first.length]
+	if !bytes.Equal(payloadHash(payload), first.hash) {
+		return nil, fmt.Errorf("reconstructed payload doesn't match its hash")
+	}
+	return payload, nil
+}
+
+// bytes serializes the frame as magic, hash, uvarint length, block size and sequence number, followed by data
+func (fr frame) bytes() []byte {
+	output := append([]byte(FRAME_MAGIC), fr.hash...)
+	output = binary.AppendUvarint(output, uint64(fr.length))
+	output = binary.AppendUvarint(output, uint64(fr.blockSize))
+	output = binary.AppendUvarint(output, fr.sequence)
+	return append(output, fr.data...)
+}
+
+func parseFrame(data []byte) (*frame, error) {
+	if !bytes.HasPrefix(data, []byte(FRAME_MAGIC)) || len(data) < len(FRAME_MAGIC)+hashLength {
+		return nil, fmt.Errorf("not a fountain-coded frame")
+	}
+	fr := &frame{hash: data[len(FRAME_MAGIC) : len(FRAME_MAGIC)+hashLength]}
+	rest := data[len(FRAME_MAGIC)+hashLength:]
+	var fields [3]uint64
+	for i := range fields {
+		value, n := binary.Uvarint(rest)
+		if n <= 0 {
+			return nil, fmt.Errorf("malformed frame header")
+		}
+		fields[i], rest = value, rest[n:]
+	}
+	if fields[0] == 0 || fields[1] == 0 || fields[1] > math.MaxUint16 || fields[0] > math.MaxInt32 {
+		return nil, fmt.Errorf("invalid frame header")
+	}
+	fr.length, fr.blockSize, fr.sequence = int(fields[0]), int(fields[1]), fields[2]
+	if len(rest) != fr.blockSize {
+		return nil, fmt.Errorf("frame %d is truncated", fr.sequence)
+	}
+	fr.data = append([]byte(nil), rest...)
+	return fr, nil
+}
+
+// frameBlocks returns indices of the blocks combined in the frame
+func frameBlocks(sequence uint64, count int, cdf []float64) []int {
+	if sequence < uint64(count) {
+		return []int{int(sequence)}
+	}
+	rng := splitmix64(sequence)
+	sample := float64(rng.next()>>11) / (1 << 53)
+	degree := 1
+	for degree < count && cdf[degree-1] < sample {
+		degree++
+	}
+	// partial Fisher-Yates shuffle picks distinct indices
+	indices := make([]int, count)
+	for i := range indices {
+		indices[i] = i
+	}
+	for i := 0; i < degree; i++ {
+		j := i + int(rng.next()%uint64(count-i))
+		indices[i], indices[j] = indices[j], indices[i]
+	}
+	return indices[:degree]
+}
+
+// solitonCDF returns cumulative robust soliton distribution of degrees 1..count
+func solitonCDF(count int) []float64 {
+	k := float64(count)
+	r := solitonC * math.Log(k/solitonDelta) * math.Sqrt(k)
+	spike := int(math.Floor(k / r))
+	weights := make([]float64, count)
+	sum := 0.0
+	for d := 1; d <= count; d++ {
+		rho := 1 / k
+		if d > 1 {
+			rho = 1 / float64(d*(d-1))
+		}
+		tau := 0.0
+		switch {
+		case d < spike:
+			tau = r / (float64(d) * k)
+		case d == spike:
+			tau = r * math.Log(r/solitonDelta) / k
+		}
+		weights[d-1] = rho + tau
+		sum += weights[d-1]
+	}
+	cumulative := 0.0
+	for i, weight := range weights {
+		cumulative += weight / sum
+		weights[i] = cumulative
+	}
+	return weights
+}
+
+func split(payload []byte, blockSize int) [][]byte {
+	var blocks [][]byte
+	for start := 0; start < len(payload); start += blockSize {
+		block := make([]byte, blockSize)
+		copy(block, payload[start:])
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+func xor(dst []byte, src []byte) {
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
+
+func payloadHash(payload []byte) []byte {
+	sum := sha256.Sum256(payload)
+	return sum[:hashLength]
+}
+
+// splitmix64 is a tiny deterministic generator, frames have to be reproducible on any platform
+type splitmix64 uint64
+
+func (s *splitmix64) next() uint64 {
+	*s += 0x9e3779b97f4a7c15
+	z := uint64(*s)
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
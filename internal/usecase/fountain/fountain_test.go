@@ -0,0 +1,58 @@
+package fountain
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestFountain(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	payload := make([]byte, 10_000)
+	rnd.Read(payload)
+	fountain := NewFountain(Config{BlockSize: 200, Frames: 150})
+
+	frames, err := fountain.Encode(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 150 || !fountain.IsFrame(frames[0]) {
+		t.Fatalf("unexpected frames: %d", len(frames))
+	}
+
+	t.Run("any subset", func(t *testing.T) {
+		// lose a third of the frames and receive the rest in random order with duplicates
+		rnd.Shuffle(len(frames), func(i, j int) { frames[i], frames[j] = frames[j], frames[i] })
+		received := append(frames[:100:100], frames[:10]...)
+		decoded, err := fountain.Decode(received)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decoded, payload) {
+			t.Fatal("decoded payload doesn't match")
+		}
+	})
+
+	t.Run("incomplete", func(t *testing.T) {
+		_, err := fountain.Decode(frames[:20])
+		var incomplete *IncompleteError
+		if !errors.As(err, &incomplete) || incomplete.Total != 50 || incomplete.Recovered >= 50 {
+			t.Fatalf("expected IncompleteError, got: %v", err)
+		}
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		other, _ := NewFountain(Config{BlockSize: 200}).Encode(payload[:5000])
+		if _, err := fountain.Decode([][]byte{frames[0], other[0]}); err == nil {
+			t.Fatal("expected an error for frames of different payloads")
+		}
+	})
+	t.Run("oversized", func(t *testing.T) {
+		// a crafted frame declaring 2^31-1 blocks of a single byte mustn't be allocated
+		crafted := frame{hash: make([]byte, hashLength), length: 1<<31 - 1, blockSize: 1, sequence: 0, data: []byte{0}}
+		if _, err := fountain.Decode([][]byte{crafted.bytes()}); err == nil {
+			t.Fatal("expected an error for a frame declaring too many blocks")
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package kdf
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"fmt"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// SCRYPT is scrypt key derivation function
+const SCRYPT = "scrypt"
+
+// PBKDF2 is PBKDF2 key derivation function with HMAC-SHA256
+const PBKDF2 = "pbkdf2"
+
+// PRF_HMAC_SHA256 is the only pseudorandom function of PBKDF2 defined by keystore formats
+const PRF_HMAC_SHA256 = "hmac-sha256"
+
+// DERIVED_KEY_LENGTH is the minimal derived key length, the first half is the cipher key
+// and the second one authenticates the ciphertext
+const DERIVED_KEY_LENGTH = 32
+
+const (
+	scryptR = 8
+	scryptP = 1
+)
+
+// Params holds parameters of both functions, only the relevant ones are set
+type Params struct {
+	Function string
+	DKLen    int
+	Salt     []byte
+	N        int
+	R        int
+	P        int
+	C        int
+	PRF      string
+}
+
+// NewParams returns parameters of the function for a new keystore, scrypt is used by default
+func NewParams(function string, scryptN int, iterations int, salt []byte) (*Params, error) {
+	params := &Params{Function: function, DKLen: DERIVED_KEY_LENGTH, Salt: salt}
+	switch function {
+	case SCRYPT, "":
+		params.Function = SCRYPT
+		params.N, params.R, params.P = scryptN, scryptR, scryptP
+	case PBKDF2:
+		params.C, params.PRF = iterations, PRF_HMAC_SHA256
+	default:
+		return nil, fmt.Errorf("unsupported key derivation function: %q", function)
+	}
+	return params, nil
+}
+
+// DeriveKey derives the key from the password, the parameters usually come from an untrusted file
+func DeriveKey(password []byte, params Params) ([]byte, error) {
+	if params.DKLen < DERIVED_KEY_LENGTH {
+		return nil, fmt.Errorf("derived key length is too short: %d", params.DKLen)
+	}
+	switch params.Function {
+	case SCRYPT:
+		key, err := scrypt.Key(password, params.Salt, params.N, params.R, params.P, params.DKLen)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive key: %w", err)
+		}
+		return key, nil
+	case PBKDF2:
+		if params.PRF != PRF_HMAC_SHA256 {
+			return nil, fmt.Errorf("unsupported PBKDF2 pseudorandom function: %q", params.PRF)
+		}
+		if params.C <= 0 {
+			return nil, fmt.Errorf("invalid PBKDF2 iterations: %d", params.C)
+		}
+		return pbkdf2.Key(password, params.Salt, params.C, params.DKLen, sha256.New), nil
+	}
+	return nil, fmt.Errorf("unsupported key derivation function: %q", params.Function)
+}
+
+// AESCTR encrypts or decrypts the input with AES in counter mode
+func AESCTR(key []byte, iv []byte, input []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cipher: %w", err)
+	}
+	if len(iv) != block.BlockSize() {
+		return nil, fmt.Errorf("invalid IV length: %d bytes", len(iv))
+	}
+	output := make([]byte, len(input))
+	cipher.NewCTR(block, iv).XORKeyStream(output, input)
+	return output, nil
+}
@@ -0,0 +1,58 @@
+package kdf
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestDeriveKey(t *testing.T) {
+	// PBKDF2-HMAC-SHA256 test vector of RFC 7914
+	params := Params{Function: PBKDF2, DKLen: 64, Salt: []byte("salt"), C: 1, PRF: PRF_HMAC_SHA256}
+	key, err := DeriveKey([]byte("passwd"), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc" +
+		"49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"
+	if hex.EncodeToString(key) != expected {
+		t.Fatalf("unexpected key: %x", key)
+	}
+
+	for name, params := range map[string]Params{
+		"short key":     {Function: PBKDF2, DKLen: 16, C: 1, PRF: PRF_HMAC_SHA256},
+		"unknown PRF":   {Function: PBKDF2, DKLen: 32, C: 1, PRF: "hmac-sha512"},
+		"no iterations": {Function: PBKDF2, DKLen: 32, PRF: PRF_HMAC_SHA256},
+		"bad scrypt N":  {Function: SCRYPT, DKLen: 32, N: 3, R: 8, P: 1},
+		"unknown":       {Function: "argon2", DKLen: 32},
+	} {
+		if _, err := DeriveKey([]byte("passwd"), params); err == nil {
+			t.Fatalf("expected an error for %s", name)
+		}
+	}
+}
+
+func TestNewParams(t *testing.T) {
+	params, err := NewParams("", 1<<12, 0, []byte("salt"))
+	if err != nil || params.Function != SCRYPT || params.N != 1<<12 || params.R != scryptR {
+		t.Fatalf("unexpected default parameters: %+v, %v", params, err)
+	}
+	if _, err := NewParams("argon2", 0, 0, nil); err == nil {
+		t.Fatal("expected an error for unknown function")
+	}
+}
+
+func TestAESCTR(t *testing.T) {
+	key, iv := bytes.Repeat([]byte{1}, 16), bytes.Repeat([]byte{2}, 16)
+	ciphertext, err := AESCTR(key, iv, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext, err := AESCTR(key, iv, ciphertext)
+	if err != nil || string(plaintext) != "secret" {
+		t.Fatalf("round trip failed: %q, %v", plaintext, err)
+	}
+	if _, err := AESCTR(key, iv[:8], ciphertext); err == nil {
+		t.Fatal("expected an error for short IV")
+	}
+}
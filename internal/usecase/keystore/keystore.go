@@ -0,0 +1,254 @@
+package keystore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/hmac"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/d347h-eth/aesgcm/internal/usecase/kdf"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"golang.org/x/crypto/sha3"
+)
+
+// VERSION is the version of Web3 Secret Storage format
+const VERSION = 3
+
+// KDF_SCRYPT is scrypt key derivation function
+const KDF_SCRYPT = kdf.SCRYPT
+
+// KDF_PBKDF2 is PBKDF2 key derivation function with HMAC-SHA256
+const KDF_PBKDF2 = kdf.PBKDF2
+
+// KDFs lists all supported key derivation functions
+var KDFs = []string{KDF_SCRYPT, KDF_PBKDF2}
+
+const (
+	cipherName       = "aes-128-ctr"
+	saltLength       = 32
+	privateKeyLength = 32
+)
+
+type (
+	// Keystore is a component responsible for encryption and decryption of Ethereum private keys
+	// in Web3 Secret Storage (V3) format, the keystore files of geth and most wallets
+	Keystore struct {
+		cfg Config
+		rnd RandomnessProvider
+	}
+
+	// Config ...
+	Config struct {
+		// KDF is the key derivation function (scrypt, pbkdf2)
+		KDF string
+		// ScryptN is CPU/memory cost of scrypt
+		ScryptN int
+		// PBKDF2Iterations is the amount of PBKDF2 iterations
+		PBKDF2Iterations int
+	}
+
+	// RandomnessProvider ...
+	RandomnessProvider interface {
+		GetRandomBytes(length int) ([]byte, error)
+	}
+
+	// keystoreJSON is the keystore file, older wallets write "Crypto" key which is matched as well
+	keystoreJSON struct {
+		Address string     `json:"address,omitempty"`
+		Crypto  cryptoJSON `json:"crypto"`
+		ID      string     `json:"id"`
+		Version int        `json:"version"`
+	}
+
+	cryptoJSON struct {
+		Cipher       string           `json:"cipher"`
+		CipherText   string           `json:"ciphertext"`
+		CipherParams cipherParamsJSON `json:"cipherparams"`
+		KDF          string           `json:"kdf"`
+		KDFParams    kdfParamsJSON    `json:"kdfparams"`
+		MAC          string           `json:"mac"`
+	}
+
+	cipherParamsJSON struct {
+		IV string `json:"iv"`
+	}
+
+	// kdfParamsJSON holds parameters of both functions, only the relevant ones are written
+	kdfParamsJSON struct {
+		DKLen int    `json:"dklen"`
+		Salt  string `json:"salt"`
+		N     int    `json:"n,omitempty"`
+		R     int    `json:"r,omitempty"`
+		P     int    `json:"p,omitempty"`
+		C     int    `json:"c,omitempty"`
+		PRF   string `json:"prf,omitempty"`
+	}
+)
+
+// NewKeystore ...
+func NewKeystore(cfg Config, rnd RandomnessProvider) *Keystore {
+	return &Keystore{cfg, rnd}
+}
+
+// Encrypt encrypts the private key into a keystore file
+func (k Keystore) Encrypt(privateKey []byte, password []byte) ([]byte, error) {
+	address, err := k.Address(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	salt, err := k.rnd.GetRandomBytes(saltLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	iv, err := k.rnd.GetRandomBytes(aes.BlockSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate IV: %w", err)
+	}
+	id, err := k.rnd.GetRandomBytes(16)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ID: %w", err)
+	}
+	// random UUID (version 4, RFC 4122 variant)
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+
+	params, err := kdf.NewParams(k.cfg.KDF, k.cfg.ScryptN, k.cfg.PBKDF2Iterations, salt)
+	if err != nil {
+		return nil, err
+	}
+	keystore := keystoreJSON{
+		Address: strings.ToLower(strings.TrimPrefix(address, "0x")),
+		Crypto: cryptoJSON{
+			Cipher:       cipherName,
+			CipherParams: cipherParamsJSON{IV: hex.EncodeToString(iv)},
+			KDF:          params.Function,
+			KDFParams: kdfParamsJSON{
+				DKLen: params.DKLen,
+				Salt:  hex.EncodeToString(params.Salt),
+				N:     params.N,
+				R:     params.R,
+				P:     params.P,
+				C:     params.C,
+				PRF:   params.PRF,
+			},
+		},
+		ID:      fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:]),
+		Version: VERSION,
+	}
+	derivedKey, err := kdf.DeriveKey(password, *params)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := kdf.AESCTR(derivedKey[:16], iv, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	keystore.Crypto.CipherText = hex.EncodeToString(ciphertext)
+	keystore.Crypto.MAC = hex.EncodeToString(mac(derivedKey, ciphertext))
+	return json.MarshalIndent(keystore, "", "  ")
+}
+
+// Decrypt verifies the MAC of the keystore file and decrypts the private key
+func (k Keystore) Decrypt(data []byte, password []byte) ([]byte, error) {
+	keystore, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if keystore.Crypto.Cipher != cipherName {
+		return nil, fmt.Errorf("unsupported keystore cipher: %q", keystore.Crypto.Cipher)
+	}
+	ciphertext, err := hex.DecodeString(keystore.Crypto.CipherText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keystore ciphertext: %w", err)
+	}
+	iv, err := hex.DecodeString(keystore.Crypto.CipherParams.IV)
+	if err != nil || len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("invalid keystore IV: %q", keystore.Crypto.CipherParams.IV)
+	}
+	expectedMAC, err := hex.DecodeString(keystore.Crypto.MAC)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keystore MAC: %w", err)
+	}
+	salt, err := hex.DecodeString(keystore.Crypto.KDFParams.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keystore salt: %w", err)
+	}
+	params := keystore.Crypto.KDFParams
+	derivedKey, err := kdf.DeriveKey(password, kdf.Params{
+		Function: keystore.Crypto.KDF,
+		DKLen:    params.DKLen,
+		Salt:     salt,
+		N:        params.N,
+		R:        params.R,
+		P:        params.P,
+		C:        params.C,
+		PRF:      params.PRF,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(mac(derivedKey, ciphertext), expectedMAC) {
+		return nil, fmt.Errorf("MAC mismatch: the password is wrong or the keystore has been damaged")
+	}
+	privateKey, err := kdf.AESCTR(derivedKey[:16], iv, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if keystore.Address != "" {
+		address, err := k.Address(privateKey)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(strings.TrimPrefix(keystore.Address, "0x"), strings.TrimPrefix(address, "0x")) {
+			return nil, fmt.Errorf("the private key doesn't match the keystore address 0x%s", keystore.Address)
+		}
+	}
+	return privateKey, nil
+}
+
+// Address returns EIP-55 checksummed Ethereum address of the private key
+func (k Keystore) Address(privateKey []byte) (string, error) {
+	if len(privateKey) != privateKeyLength {
+		return "", fmt.Errorf("invalid private key length: %d bytes", len(privateKey))
+	}
+	var scalar secp256k1.ModNScalar
+	if overflow := scalar.SetByteSlice(privateKey); overflow || scalar.IsZero() {
+		return "", fmt.Errorf("invalid private key: out of the curve order")
+	}
+	publicKey := secp256k1.NewPrivateKey(&scalar).PubKey().SerializeUncompressed()
+	hash := keccak256(publicKey[1:])
+	address := hex.EncodeToString(hash[12:])
+	checksum := hex.EncodeToString(keccak256([]byte(address)))
+	output := []byte(address)
+	for i := range output {
+		if output[i] >= 'a' && checksum[i] >= '8' {
+			output[i] -= 'a' - 'A'
+		}
+	}
+	return "0x" + string(output), nil
+}
+
+func parse(data []byte) (*keystoreJSON, error) {
+	keystore := &keystoreJSON{}
+	if err := json.Unmarshal(bytes.Replace(data, []byte(`"Crypto"`), []byte(`"crypto"`), 1), keystore); err != nil {
+		return nil, fmt.Errorf("failed to parse keystore: %w", err)
+	}
+	if keystore.Version != VERSION {
+		return nil, fmt.Errorf("unsupported keystore version: %d", keystore.Version)
+	}
+	return keystore, nil
+}
+
+// mac is Keccak-256 of the second half of the derived key and the ciphertext
+func mac(derivedKey []byte, ciphertext []byte) []byte {
+	return keccak256(append(append([]byte(nil), derivedKey[16:32]...), ciphertext...))
+}
+
+func keccak256(data []byte) []byte {
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write(data)
+	return hash.Sum(nil)
+}
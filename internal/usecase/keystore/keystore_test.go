@@ -0,0 +1,95 @@
+package keystore
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/d347h-eth/aesgcm/internal/infra/randomness"
+)
+
+// test vectors of Web3 Secret Storage Definition
+var testVectors = map[string]string{
+	KDF_PBKDF2: `{
+		"crypto" : {
+			"cipher" : "aes-128-ctr",
+			"cipherparams" : {"iv" : "6087dab2f9fdbbfaddc31a909735c1e6"},
+			"ciphertext" : "5318b4d5bcd28de64ee5559e671353e16f075ecae9f99c7a79a38af5f869aa46",
+			"kdf" : "pbkdf2",
+			"kdfparams" : {
+				"c" : 262144,
+				"dklen" : 32,
+				"prf" : "hmac-sha256",
+				"salt" : "ae3cd4e7013836a3df6bd7241b12db061dbe2c6785853cce422d148a624ce0bd"
+			},
+			"mac" : "517ead924a9d0dc3124507e3393d175ce3ff7c1e96529c6c555ce9e51205e9b2"
+		},
+		"id" : "3198bc9c-6672-5ab3-d995-4942343ae5b6",
+		"version" : 3
+	}`,
+	KDF_SCRYPT: `{
+		"crypto" : {
+			"cipher" : "aes-128-ctr",
+			"cipherparams" : {"iv" : "83dbcc02d8ccb40e466191a123791e0e"},
+			"ciphertext" : "d172bf743a674da9cdad04534d56926ef8358534d458fffccd4e6ad2fbde479c",
+			"kdf" : "scrypt",
+			"kdfparams" : {
+				"dklen" : 32,
+				"n" : 262144,
+				"r" : 1,
+				"p" : 8,
+				"salt" : "ab0c7876052600dd703518d6fc3fe8984592145b591fc8fb5c6d43190334ba19"
+			},
+			"mac" : "2103ac29920d71da29f15d75b4a16dbe95cfd7ff8faea1056c33131d846e3097"
+		},
+		"id" : "3198bc9c-6672-5ab3-d995-4942343ae5b6",
+		"version" : 3
+	}`,
+}
+
+func TestDecrypt(t *testing.T) {
+	expected, _ := hex.DecodeString("7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d")
+	keystore := NewKeystore(Config{}, randomness.NewOSRandomness())
+	for kdf, vector := range testVectors {
+		privateKey, err := keystore.Decrypt([]byte(vector), []byte("testpassword"))
+		if err != nil {
+			t.Fatalf("failed to decrypt %s test vector: %s", kdf, err)
+		}
+		if !bytes.Equal(privateKey, expected) {
+			t.Fatalf("unexpected private key of %s test vector: %x", kdf, privateKey)
+		}
+		if _, err := keystore.Decrypt([]byte(vector), []byte("wrongpassword")); err == nil || !strings.Contains(err.Error(), "MAC mismatch") {
+			t.Fatalf("expected MAC mismatch of %s test vector, got: %v", kdf, err)
+		}
+	}
+}
+
+func TestEncrypt(t *testing.T) {
+	privateKey := make([]byte, 32)
+	privateKey[31] = 1
+	address, err := NewKeystore(Config{}, randomness.NewOSRandomness()).Address(privateKey)
+	if err != nil || address != "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf" {
+		t.Fatalf("unexpected address of private key 1: %s, %v", address, err)
+	}
+	for _, kdf := range KDFs {
+		keystore := NewKeystore(Config{KDF: kdf, ScryptN: 1 << 12, PBKDF2Iterations: 1000}, randomness.NewOSRandomness())
+		data, err := keystore.Encrypt(privateKey, []byte("password"))
+		if err != nil {
+			t.Fatalf("failed to encrypt with %s: %s", kdf, err)
+		}
+		if !strings.Contains(string(data), `"address": "7e5f4552091a69125d5dfcb7b8c2659029395bdf"`) {
+			t.Fatalf("keystore doesn't contain the address: %s", data)
+		}
+		decrypted, err := keystore.Decrypt(data, []byte("password"))
+		if err != nil {
+			t.Fatalf("failed to decrypt with %s: %s", kdf, err)
+		}
+		if !bytes.Equal(decrypted, privateKey) {
+			t.Fatalf("decrypted private key doesn't match")
+		}
+	}
+	if _, err := NewKeystore(Config{}, randomness.NewOSRandomness()).Address(make([]byte, 32)); err == nil {
+		t.Fatalf("zero private key has been accepted")
+	}
+}